@@ -2,9 +2,8 @@ package notionagents
 
 import (
 	"context"
+	"errors"
 	"fmt"
-	"math"
-	"math/rand"
 	"net/url"
 	"strconv"
 	"time"
@@ -37,10 +36,7 @@ type fileUploadRef struct {
 // Chat starts an async chat with the agent.
 func (a *Agent) Chat(ctx context.Context, params ChatParams) (*ChatInvocationResponse, error) {
 	if params.Message == "" && len(params.Attachments) == 0 {
-		return nil, &NotionAgentsError{
-			Msg:  "Either message or attachments is required.",
-			Code: "validation_error",
-		}
+		return nil, newValidationError("Either message or attachments is required.")
 	}
 
 	body := chatRequestBody{
@@ -54,6 +50,7 @@ func (a *Agent) Chat(ctx context.Context, params ChatParams) (*ChatInvocationRes
 		})
 	}
 
+	ctx = withOperation(ctx, Operation{Name: OpAgentChat, AgentID: a.ID, ThreadID: params.ThreadID})
 	var resp ChatInvocationResponse
 	path := fmt.Sprintf("v1/agents/%s/chat", a.ID)
 	if err := a.client.doJSON(ctx, "POST", path, body, &resp); err != nil {
@@ -62,6 +59,16 @@ func (a *Agent) Chat(ctx context.Context, params ChatParams) (*ChatInvocationRes
 	return &resp, nil
 }
 
+// ChatWithFiles uploads each file at paths and starts an async chat with
+// them attached, named after each file's base name.
+func (a *Agent) ChatWithFiles(ctx context.Context, message string, paths ...string) (*ChatInvocationResponse, error) {
+	attachments, err := a.client.FileUploads.uploadAttachments(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	return a.Chat(ctx, ChatParams{Message: message, Attachments: attachments})
+}
+
 // Thread returns a Thread handle.
 func (a *Agent) Thread(threadID string) *Thread {
 	return &Thread{
@@ -119,7 +126,12 @@ func (a *Agent) PollThread(ctx context.Context, threadID string, opts *PollThrea
 			}
 		} else {
 			switch item.Status {
-			case ThreadStatusCompleted, ThreadStatusFailed:
+			case ThreadStatusFailed:
+				if opts.Strict {
+					return nil, a.threadFailedError(ctx, threadID, *item)
+				}
+				return item, nil
+			case ThreadStatusCompleted:
 				return item, nil
 			case ThreadStatusPending:
 				if opts.OnPending != nil {
@@ -129,24 +141,53 @@ func (a *Agent) PollThread(ctx context.Context, threadID string, opts *PollThrea
 		}
 
 		// Exponential backoff with jitter
-		exponentialDelay := float64(baseDelay) * math.Pow(2, float64(attempt))
-		jitter := rand.Float64() * float64(baseDelay)
-		delay := math.Min(exponentialDelay+jitter, float64(maxDelay))
-
-		select {
-		case <-ctx.Done():
-			return nil, ctx.Err()
-		case <-time.After(time.Duration(delay) * time.Millisecond):
+		if err := sleepContext(ctx, backoffDelay(attempt, baseDelay, maxDelay, true)); err != nil {
+			return nil, err
 		}
 	}
 
 	return nil, &PollingTimeoutError{Attempts: maxAttempts}
 }
 
-// isThreadNotFound checks if an error is a ThreadNotFoundError.
+// threadFailedError builds a ThreadFailedError for a failed thread, using its
+// verbose messages to find the last agent message and any tool errors from
+// the final turn. If the messages cannot be fetched, the error carries only
+// the thread details.
+func (a *Agent) threadFailedError(ctx context.Context, threadID string, item ThreadListItem) *ThreadFailedError {
+	failed := &ThreadFailedError{
+		ThreadID:     threadID,
+		Thread:       item,
+		AgentVersion: item.AgentVersion,
+	}
+
+	verbose := true
+	messages, err := CollectMessages(ctx, a.Thread(threadID), &ThreadMessageListParams{Verbose: &verbose})
+	if err != nil {
+		return failed
+	}
+
+	// Only inspect the final turn, after the last user message.
+	turn := finalTurn(messages)
+
+	if reply, ok := lastAgentMessage(turn); ok {
+		failed.LastMessage = &reply
+	}
+	for _, msg := range turn {
+		for _, part := range msg.ContentParts {
+			for _, result := range part.Results {
+				if result.Error != nil {
+					failed.ToolErrors = append(failed.ToolErrors, result)
+				}
+			}
+		}
+	}
+	return failed
+}
+
+// isThreadNotFound checks if an error is or wraps a ThreadNotFoundError.
 func isThreadNotFound(err error) bool {
-	_, ok := err.(*ThreadNotFoundError)
-	return ok
+	var tnf *ThreadNotFoundError
+	return errors.As(err, &tnf)
 }
 
 // ListThreads returns a paginated list of threads for this agent.
@@ -180,6 +221,7 @@ func (a *Agent) ListThreads(ctx context.Context, params *ThreadListParams) (*Thr
 		}
 	}
 
+	ctx = withOperation(ctx, Operation{Name: OpAgentThreads, AgentID: a.ID})
 	var resp ThreadListResponse
 	if err := a.client.doJSON(ctx, "GET", path, nil, &resp); err != nil {
 		return nil, err
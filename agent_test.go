@@ -3,8 +3,13 @@ package notionagents
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"io/fs"
 	"net/http"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 )
@@ -55,12 +60,12 @@ func TestAgentChatValidation(t *testing.T) {
 		t.Fatal("expected validation error, got nil")
 	}
 
-	ne, ok := err.(*NotionAgentsError)
-	if !ok {
-		t.Fatalf("expected *NotionAgentsError, got %T", err)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("err = %v, want ErrValidation", err)
 	}
-	if ne.Code != "validation_error" {
-		t.Errorf("Code = %q, want %q", ne.Code, "validation_error")
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Code != "validation_error" {
+		t.Errorf("err = %#v, want *ValidationError with code validation_error", err)
 	}
 }
 
@@ -228,12 +233,12 @@ func TestStreamValidation(t *testing.T) {
 		t.Fatal("expected validation error, got nil")
 	}
 
-	ne, ok := err.(*NotionAgentsError)
-	if !ok {
-		t.Fatalf("expected *NotionAgentsError, got %T", err)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("err = %v, want ErrValidation", err)
 	}
-	if ne.Code != "validation_error" {
-		t.Errorf("Code = %q, want %q", ne.Code, "validation_error")
+	var ve *ValidationError
+	if !errors.As(err, &ve) || ve.Code != "validation_error" {
+		t.Errorf("err = %#v, want *ValidationError with code validation_error", err)
 	}
 }
 
@@ -259,3 +264,172 @@ func TestStreamHTTPError(t *testing.T) {
 		t.Errorf("Code = %q, want %q", se.Code, "http_error")
 	}
 }
+
+func TestAgentChatWithFiles(t *testing.T) {
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "sales.csv")
+	pdfPath := filepath.Join(dir, "notes.pdf")
+	os.WriteFile(csvPath, []byte("a,b\n1,2\n"), 0o600)
+	os.WriteFile(pdfPath, []byte("%PDF-1.7\n"), 0o600)
+
+	uploads := 0
+	var chatBody chatRequestBody
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/v1/file_uploads":
+			uploads++
+			var params FileUploadCreateParams
+			data, _ := io.ReadAll(req.Body)
+			json.Unmarshal(data, &params)
+			return jsonResponse(200, FileUpload{ID: fmt.Sprintf("fu-%d", uploads), Filename: params.Filename}), nil
+		case strings.HasSuffix(req.URL.Path, "/send"):
+			id := strings.Split(req.URL.Path, "/")[3]
+			return jsonResponse(200, FileUpload{ID: id, Status: FileUploadStatusUploaded}), nil
+		case req.URL.Path == "/v1/agents/agent-1/chat":
+			data, _ := io.ReadAll(req.Body)
+			json.Unmarshal(data, &chatBody)
+			return jsonResponse(200, ChatInvocationResponse{ThreadID: "thread-1", Status: "pending"}), nil
+		}
+		t.Fatalf("unexpected request %s", req.URL.Path)
+		return nil, nil
+	})
+
+	resp, err := c.Agents.Agent("agent-1").ChatWithFiles(context.Background(), "review these", csvPath, pdfPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ThreadID != "thread-1" {
+		t.Errorf("ThreadID = %q, want %q", resp.ThreadID, "thread-1")
+	}
+	if chatBody.Message != "review these" {
+		t.Errorf("Message = %q, want %q", chatBody.Message, "review these")
+	}
+	if len(chatBody.Attachments) != 2 {
+		t.Fatalf("attachments len = %d, want 2", len(chatBody.Attachments))
+	}
+	if chatBody.Attachments[0].FileUpload.ID != "fu-1" || chatBody.Attachments[0].Name != "sales.csv" {
+		t.Errorf("attachment[0] = %+v, want fu-1 sales.csv", chatBody.Attachments[0])
+	}
+	if chatBody.Attachments[1].FileUpload.ID != "fu-2" || chatBody.Attachments[1].Name != "notes.pdf" {
+		t.Errorf("attachment[1] = %+v, want fu-2 notes.pdf", chatBody.Attachments[1])
+	}
+}
+
+func TestAgentChatWithFilesMissingFile(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("no request should be sent")
+		return nil, nil
+	})
+
+	_, err := c.Agents.Agent("agent-1").ChatWithFiles(context.Background(), "hi", filepath.Join(t.TempDir(), "missing.csv"))
+	if !errors.Is(err, fs.ErrNotExist) {
+		t.Errorf("err = %v, want fs.ErrNotExist", err)
+	}
+}
+
+func TestAgentStreamWithFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data.csv")
+	os.WriteFile(path, []byte("x\n"), 0o600)
+
+	var chatBody chatRequestBody
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/v1/file_uploads":
+			return jsonResponse(200, FileUpload{ID: "fu-1", Filename: "data.csv"}), nil
+		case strings.HasSuffix(req.URL.Path, "/send"):
+			return jsonResponse(200, FileUpload{ID: "fu-1", Filename: "data.csv"}), nil
+		}
+		data, _ := io.ReadAll(req.Body)
+		json.Unmarshal(data, &chatBody)
+		return ndjsonResponse(StreamChunk{Type: "done"}), nil
+	})
+
+	reader, err := c.Agents.Agent("agent-1").StreamWithFiles(context.Background(), "summarize", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	if len(chatBody.Attachments) != 1 || chatBody.Attachments[0].Name != "data.csv" {
+		t.Errorf("attachments = %+v, want data.csv", chatBody.Attachments)
+	}
+}
+
+func TestPollThreadStrictFailure(t *testing.T) {
+	toolErr := "search index unavailable"
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		if strings.HasSuffix(req.URL.Path, "/threads") {
+			return jsonResponse(200, ThreadListResponse{
+				Object: "list",
+				Results: []ThreadListItem{{
+					ID:           "t-1",
+					Status:       ThreadStatusFailed,
+					AgentVersion: &AgentVersion{ID: "v-1", Number: 4},
+				}},
+			}), nil
+		}
+		if req.URL.Query().Get("verbose") != "true" {
+			t.Errorf("verbose = %q, want true", req.URL.Query().Get("verbose"))
+		}
+		oldErr := "stale error from an earlier turn"
+		return jsonResponse(200, ThreadMessageListResponse{
+			Object: "list",
+			Results: []ThreadMessageItem{
+				{ID: "m-1", Role: MessageRoleUser, Content: "first"},
+				{ID: "m-2", Role: MessageRoleAgent, ContentParts: []AgentContentPart{
+					{Type: "tool_call", ToolName: "search", Results: []ToolResult{{ToolName: "search", Error: &oldErr}}},
+				}},
+				{ID: "m-3", Role: MessageRoleUser, Content: "second"},
+				{ID: "m-4", Role: MessageRoleAgent, Content: "Let me search", ContentParts: []AgentContentPart{
+					{Type: "tool_call", ToolName: "search", Results: []ToolResult{
+						{ToolName: "search", State: "done"},
+						{ToolName: "search", Error: &toolErr},
+					}},
+				}},
+			},
+		}), nil
+	})
+
+	_, err := c.Agents.Agent("agent-1").PollThread(context.Background(), "t-1", &PollThreadOptions{
+		InitialDelayMs: 1,
+		Strict:         true,
+	})
+
+	var tfe *ThreadFailedError
+	if !errors.As(err, &tfe) {
+		t.Fatalf("expected *ThreadFailedError, got %T (%v)", err, err)
+	}
+	if tfe.ThreadID != "t-1" {
+		t.Errorf("ThreadID = %q, want %q", tfe.ThreadID, "t-1")
+	}
+	if tfe.AgentVersion == nil || tfe.AgentVersion.Number != 4 {
+		t.Errorf("AgentVersion = %+v, want number 4", tfe.AgentVersion)
+	}
+	if tfe.LastMessage == nil || tfe.LastMessage.ID != "m-4" {
+		t.Errorf("LastMessage = %+v, want m-4", tfe.LastMessage)
+	}
+	if len(tfe.ToolErrors) != 1 || *tfe.ToolErrors[0].Error != toolErr {
+		t.Errorf("ToolErrors = %+v, want only the final turn's error", tfe.ToolErrors)
+	}
+	want := "thread failed: t-1 (agent version 4): tool search: search index unavailable"
+	if err.Error() != want {
+		t.Errorf("Error() = %q, want %q", err.Error(), want)
+	}
+}
+
+func TestPollThreadNonStrictReturnsFailedThread(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(200, ThreadListResponse{
+			Object:  "list",
+			Results: []ThreadListItem{{ID: "t-1", Status: ThreadStatusFailed}},
+		}), nil
+	})
+
+	item, err := c.Agents.Agent("agent-1").PollThread(context.Background(), "t-1", &PollThreadOptions{InitialDelayMs: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if item.Status != ThreadStatusFailed {
+		t.Errorf("Status = %q, want %q", item.Status, ThreadStatusFailed)
+	}
+}
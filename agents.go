@@ -30,6 +30,7 @@ func (a *AgentOperations) List(ctx context.Context, params *AgentListParams) (*A
 		}
 	}
 
+	ctx = withOperation(ctx, Operation{Name: OpAgentsList})
 	var resp AgentListResponse
 	if err := a.client.doJSON(ctx, "GET", path, nil, &resp); err != nil {
 		return nil, err
@@ -0,0 +1,77 @@
+package notionagents
+
+import "context"
+
+// Ask sends a message, waits for the agent to finish and returns its final
+// reply. It combines Chat, PollThread and ListMessages into one call.
+//
+// If the thread ends with ThreadStatusFailed, Ask returns a
+// *ThreadFailedError describing the failure.
+func (a *Agent) Ask(ctx context.Context, params ChatParams, opts *AskOptions) (*AskResult, error) {
+	if opts == nil {
+		opts = &AskOptions{}
+	}
+
+	invocation, err := a.Chat(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+
+	var poll PollThreadOptions
+	if opts.Poll != nil {
+		poll = *opts.Poll
+	}
+	poll.Strict = true
+
+	item, err := a.PollThread(ctx, invocation.ThreadID, &poll)
+	if err != nil {
+		return nil, err
+	}
+
+	verbose := true
+	if opts.Verbose != nil {
+		verbose = *opts.Verbose
+	}
+	messages, err := CollectMessages(ctx, a.Thread(invocation.ThreadID), &ThreadMessageListParams{
+		Verbose: &verbose,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	reply, ok := lastAgentMessage(finalTurn(messages))
+	if !ok {
+		return nil, &NotionAgentsError{
+			Msg:  "thread completed without an agent reply",
+			Code: "missing_agent_message",
+		}
+	}
+
+	return &AskResult{
+		ThreadID: invocation.ThreadID,
+		Thread:   *item,
+		Message:  reply,
+		Messages: messages,
+	}, nil
+}
+
+// finalTurn returns the messages after the last user message, which make up
+// the agent's reply to it.
+func finalTurn(messages []ThreadMessageItem) []ThreadMessageItem {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == MessageRoleUser {
+			return messages[i+1:]
+		}
+	}
+	return messages
+}
+
+// lastAgentMessage returns the last message written by the agent.
+func lastAgentMessage(messages []ThreadMessageItem) (ThreadMessageItem, bool) {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == MessageRoleAgent {
+			return messages[i], true
+		}
+	}
+	return ThreadMessageItem{}, false
+}
@@ -0,0 +1,108 @@
+package notionagents
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+)
+
+var fastPoll = &PollThreadOptions{InitialDelayMs: 1, BaseDelayMs: 1, MaxDelayMs: 1}
+
+func askClient(t *testing.T, status ThreadStatus, messages []ThreadMessageItem) *Client {
+	return mockClient(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/v1/agents/agent-1/chat":
+			return jsonResponse(200, ChatInvocationResponse{ThreadID: "thread-1", Status: "pending"}), nil
+		case "/v1/agents/agent-1/threads":
+			return jsonResponse(200, ThreadListResponse{
+				Object:  "list",
+				Results: []ThreadListItem{{ID: "thread-1", Status: status}},
+			}), nil
+		case "/v1/threads/thread-1/messages":
+			if req.URL.Query().Get("verbose") != "true" {
+				t.Errorf("verbose = %q, want true", req.URL.Query().Get("verbose"))
+			}
+			return jsonResponse(200, ThreadMessageListResponse{Object: "list", Results: messages}), nil
+		}
+		t.Fatalf("unexpected request %s", req.URL.Path)
+		return nil, nil
+	})
+}
+
+func TestAgentAsk(t *testing.T) {
+	c := askClient(t, ThreadStatusCompleted, []ThreadMessageItem{
+		{ID: "msg-1", Role: MessageRoleUser, Content: "What's new?"},
+		{ID: "msg-2", Role: MessageRoleAgent, Content: "Thinking..."},
+		{ID: "msg-3", Role: MessageRoleAgent, Content: "Here is the summary.", ContentParts: []AgentContentPart{
+			{Type: "text", Text: "Here is the summary."},
+		}},
+	})
+
+	result, err := c.Agents.Agent("agent-1").Ask(context.Background(), ChatParams{Message: "What's new?"}, &AskOptions{Poll: fastPoll})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.ThreadID != "thread-1" {
+		t.Errorf("ThreadID = %q, want %q", result.ThreadID, "thread-1")
+	}
+	if result.Thread.Status != ThreadStatusCompleted {
+		t.Errorf("Status = %q, want %q", result.Thread.Status, ThreadStatusCompleted)
+	}
+	if result.Message.ID != "msg-3" {
+		t.Errorf("Message.ID = %q, want %q", result.Message.ID, "msg-3")
+	}
+	if len(result.Message.ContentParts) != 1 {
+		t.Errorf("ContentParts len = %d, want 1", len(result.Message.ContentParts))
+	}
+	if len(result.Messages) != 3 {
+		t.Errorf("Messages len = %d, want 3", len(result.Messages))
+	}
+}
+
+func TestAgentAskFailedThread(t *testing.T) {
+	c := askClient(t, ThreadStatusFailed, nil)
+
+	_, err := c.Agents.Agent("agent-1").Ask(context.Background(), ChatParams{Message: "hi"}, &AskOptions{Poll: fastPoll})
+	if !errors.Is(err, ErrThreadFailed) {
+		t.Fatalf("err = %v, want ErrThreadFailed", err)
+	}
+	var tfe *ThreadFailedError
+	if !errors.As(err, &tfe) || tfe.ThreadID != "thread-1" {
+		t.Errorf("ThreadFailedError = %+v, want thread-1", tfe)
+	}
+}
+
+func TestAgentAskMissingReply(t *testing.T) {
+	c := askClient(t, ThreadStatusCompleted, []ThreadMessageItem{
+		{ID: "msg-1", Role: MessageRoleUser, Content: "hi"},
+	})
+
+	_, err := c.Agents.Agent("agent-1").Ask(context.Background(), ChatParams{Message: "hi"}, &AskOptions{Poll: fastPoll})
+	ne, ok := err.(*NotionAgentsError)
+	if !ok || ne.Code != "missing_agent_message" {
+		t.Errorf("err = %v, want missing_agent_message", err)
+	}
+}
+
+func TestAgentAskIgnoresEarlierTurns(t *testing.T) {
+	c := askClient(t, ThreadStatusCompleted, []ThreadMessageItem{
+		{ID: "msg-1", Role: MessageRoleUser, Content: "first"},
+		{ID: "msg-2", Role: MessageRoleAgent, Content: "OLD REPLY"},
+		{ID: "msg-3", Role: MessageRoleUser, Content: "second"},
+	})
+
+	result, err := c.Agents.Agent("agent-1").Ask(context.Background(), ChatParams{Message: "second", ThreadID: "thread-1"}, &AskOptions{Poll: fastPoll})
+	ne, ok := err.(*NotionAgentsError)
+	if !ok || ne.Code != "missing_agent_message" {
+		t.Errorf("Ask() = %+v, %v; want missing_agent_message", result, err)
+	}
+}
+
+func TestAgentAskValidation(t *testing.T) {
+	c := NewClient(ClientOptions{Auth: "tok"})
+	_, err := c.Agents.Agent("agent-1").Ask(context.Background(), ChatParams{}, nil)
+	if !errors.Is(err, ErrValidation) {
+		t.Errorf("err = %v, want ErrValidation", err)
+	}
+}
@@ -0,0 +1,171 @@
+package notionagents
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// attachmentExpirySkew refreshes signed URLs slightly before they expire so
+// a download does not start on a URL about to become invalid.
+const attachmentExpirySkew = 30 * time.Second
+
+// Attachment provides download access to a file attached to a thread message.
+// Signed URLs that have expired are refreshed by re-fetching the owning
+// message.
+type Attachment struct {
+	ThreadMessageAttachment
+	MessageID string
+	thread    *Thread
+}
+
+// Attachment returns a handle for downloading a file attached to the given
+// message in this thread.
+func (t *Thread) Attachment(messageID string, att ThreadMessageAttachment) *Attachment {
+	return &Attachment{
+		ThreadMessageAttachment: att,
+		MessageID:               messageID,
+		thread:                  t,
+	}
+}
+
+// ExpiresAt returns the parsed ExpiryTime of the attachment's signed URL.
+// The second result is false if the URL has no expiry or it cannot be parsed.
+func (a *ThreadMessageAttachment) ExpiresAt() (time.Time, bool) {
+	if a.ExpiryTime == nil {
+		return time.Time{}, false
+	}
+	return parseExpiryTime(*a.ExpiryTime)
+}
+
+// ExpiresAt returns the parsed ExpiryTime of the signed URL. The second
+// result is false if the URL has no expiry or it cannot be parsed.
+func (f *FileURL) ExpiresAt() (time.Time, bool) {
+	return parseExpiryTime(f.ExpiryTime)
+}
+
+func parseExpiryTime(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// expired reports whether the attachment's signed URL has expired or is
+// about to.
+func (a *Attachment) expired() bool {
+	expiresAt, ok := a.ExpiresAt()
+	return ok && time.Now().Add(attachmentExpirySkew).After(expiresAt)
+}
+
+// Refresh re-fetches the owning message to obtain a fresh signed URL.
+func (a *Attachment) Refresh(ctx context.Context) error {
+	for msg, err := range IterMessages(ctx, a.thread, nil) {
+		if err != nil {
+			return err
+		}
+		if msg.ID != a.MessageID {
+			continue
+		}
+		for _, att := range msg.Attachments {
+			if att.Name == a.Name {
+				a.ThreadMessageAttachment = att
+				return nil
+			}
+		}
+		break
+	}
+	return &NotionAgentsError{
+		Msg:  fmt.Sprintf("attachment %q not found on message %s", a.Name, a.MessageID),
+		Code: "attachment_not_found",
+	}
+}
+
+// Download streams the attachment to w and returns the number of bytes
+// written. An expired URL is refreshed before downloading, and a download
+// rejected because the signature expired is retried once with a refreshed
+// URL.
+func (a *Attachment) Download(ctx context.Context, w io.Writer) (int64, error) {
+	refreshed := false
+	if a.expired() {
+		if err := a.Refresh(ctx); err != nil {
+			return 0, err
+		}
+		refreshed = true
+	}
+
+	for {
+		resp, err := a.get(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if !refreshed && (resp.StatusCode == http.StatusForbidden || resp.StatusCode == http.StatusBadRequest) {
+			resp.Body.Close()
+			if err := a.Refresh(ctx); err != nil {
+				return 0, err
+			}
+			refreshed = true
+			continue
+		}
+
+		if resp.StatusCode >= 400 {
+			defer resp.Body.Close()
+			respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+			return 0, &NotionAgentsError{
+				Msg:        fmt.Sprintf("downloading %s: HTTP %d: %s", a.Name, resp.StatusCode, string(respBody)),
+				Code:       "http_error",
+				StatusCode: resp.StatusCode,
+				Body:       respBody,
+			}
+		}
+
+		n, err := io.Copy(w, resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return n, fmt.Errorf("downloading %s: %w", a.Name, err)
+		}
+		return n, nil
+	}
+}
+
+// get requests the signed URL. Signed URLs carry their own credentials, so
+// the Notion API token is not sent.
+func (a *Attachment) get(ctx context.Context) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.URL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("creating request: %w", err)
+	}
+	return a.thread.client.httpClient.Do(req)
+}
+
+// SaveTo downloads the attachment to path. The file is written to a
+// temporary file in the same directory and renamed into place once the
+// download completes, so path never holds a partial download.
+func (a *Attachment) SaveTo(ctx context.Context, path string) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("creating file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := a.Download(ctx, tmp); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("writing file: %w", err)
+	}
+	return nil
+}
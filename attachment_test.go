@@ -0,0 +1,232 @@
+package notionagents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func expiry(d time.Duration) *string {
+	s := time.Now().Add(d).UTC().Format(time.RFC3339)
+	return &s
+}
+
+func TestAttachmentDownload(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Host != "files.example.com" {
+			t.Fatalf("unexpected request to %s", req.URL)
+		}
+		if req.Header.Get("Authorization") != "" {
+			t.Error("signed URL downloads must not send the API token")
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("file contents"))}, nil
+	})
+
+	att := c.Agents.Agent("agent-1").Thread("thread-1").Attachment("msg-1", ThreadMessageAttachment{
+		Name:       "report.csv",
+		URL:        "https://files.example.com/report.csv?sig=abc",
+		ExpiryTime: expiry(time.Hour),
+	})
+
+	var buf bytes.Buffer
+	n, err := att.Download(context.Background(), &buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len("file contents")) || buf.String() != "file contents" {
+		t.Errorf("downloaded %d bytes %q, want %q", n, buf.String(), "file contents")
+	}
+}
+
+func TestAttachmentDownloadRefreshesExpiredURL(t *testing.T) {
+	listCalls := 0
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/v1/threads/thread-1/messages" {
+			listCalls++
+			return jsonResponse(200, ThreadMessageListResponse{
+				Object: "list",
+				Results: []ThreadMessageItem{
+					{ID: "msg-0", Role: "user"},
+					{ID: "msg-1", Role: "agent", Attachments: []ThreadMessageAttachment{
+						{Name: "report.csv", URL: "https://files.example.com/fresh", ExpiryTime: expiry(time.Hour)},
+					}},
+				},
+			}), nil
+		}
+		if req.URL.Path != "/fresh" {
+			t.Fatalf("downloaded %s, want refreshed URL", req.URL)
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("fresh"))}, nil
+	})
+
+	att := c.Agents.Agent("agent-1").Thread("thread-1").Attachment("msg-1", ThreadMessageAttachment{
+		Name:       "report.csv",
+		URL:        "https://files.example.com/stale",
+		ExpiryTime: expiry(-time.Minute),
+	})
+
+	var buf bytes.Buffer
+	if _, err := att.Download(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if listCalls != 1 {
+		t.Errorf("list calls = %d, want 1", listCalls)
+	}
+	if buf.String() != "fresh" {
+		t.Errorf("content = %q, want %q", buf.String(), "fresh")
+	}
+	if att.URL != "https://files.example.com/fresh" {
+		t.Errorf("URL = %q, want refreshed URL", att.URL)
+	}
+}
+
+func TestAttachmentDownloadRefreshesOnForbidden(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/v1/threads/thread-1/messages":
+			return jsonResponse(200, ThreadMessageListResponse{
+				Object: "list",
+				Results: []ThreadMessageItem{{ID: "msg-1", Attachments: []ThreadMessageAttachment{
+					{Name: "a.txt", URL: "https://files.example.com/fresh"},
+				}}},
+			}), nil
+		case "/stale":
+			return &http.Response{StatusCode: 403, Body: io.NopCloser(strings.NewReader("expired"))}, nil
+		}
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("ok"))}, nil
+	})
+
+	att := c.Agents.Agent("agent-1").Thread("thread-1").Attachment("msg-1", ThreadMessageAttachment{
+		Name: "a.txt",
+		URL:  "https://files.example.com/stale",
+	})
+
+	var buf bytes.Buffer
+	if _, err := att.Download(context.Background(), &buf); err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != "ok" {
+		t.Errorf("content = %q, want %q", buf.String(), "ok")
+	}
+}
+
+func TestAttachmentDownloadKeepsErrorBodyAfterRefresh(t *testing.T) {
+	var srv *httptest.Server
+	srv = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/threads/thread-1/messages" {
+			json.NewEncoder(w).Encode(ThreadMessageListResponse{
+				Object: "list",
+				Results: []ThreadMessageItem{{ID: "msg-1", Role: "agent", Attachments: []ThreadMessageAttachment{
+					{Name: "report.csv", URL: srv.URL + "/files/fresh", ExpiryTime: expiry(time.Hour)},
+				}}},
+			})
+			return
+		}
+		w.WriteHeader(http.StatusForbidden)
+		io.WriteString(w, "AccessDenied: request has expired")
+	}))
+	defer srv.Close()
+
+	c := NewClient(ClientOptions{Auth: "tok", BaseURL: srv.URL, HTTPClient: srv.Client()})
+	att := c.Agents.Agent("agent-1").Thread("thread-1").Attachment("msg-1", ThreadMessageAttachment{
+		Name:       "report.csv",
+		URL:        srv.URL + "/files/stale",
+		ExpiryTime: expiry(time.Hour),
+	})
+
+	_, err := att.Download(context.Background(), io.Discard)
+	var ne *NotionAgentsError
+	if !errors.As(err, &ne) || ne.StatusCode != http.StatusForbidden {
+		t.Fatalf("err = %v, want HTTP 403 error", err)
+	}
+	if !strings.Contains(string(ne.Body), "AccessDenied") || !strings.Contains(ne.Msg, "AccessDenied") {
+		t.Errorf("error = %q with body %q, want the storage provider's reason", ne.Msg, ne.Body)
+	}
+}
+
+func TestAttachmentRefreshNotFound(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(200, ThreadMessageListResponse{Object: "list"}), nil
+	})
+
+	att := c.Agents.Agent("agent-1").Thread("thread-1").Attachment("msg-1", ThreadMessageAttachment{Name: "a.txt"})
+	err := att.Refresh(context.Background())
+	ne, ok := err.(*NotionAgentsError)
+	if !ok || ne.Code != "attachment_not_found" {
+		t.Errorf("err = %v, want attachment_not_found", err)
+	}
+}
+
+func TestAttachmentSaveTo(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: io.NopCloser(strings.NewReader("saved"))}, nil
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	att := c.Agents.Agent("agent-1").Thread("thread-1").Attachment("msg-1", ThreadMessageAttachment{
+		Name: "out.txt",
+		URL:  "https://files.example.com/out.txt",
+	})
+
+	if err := att.SaveTo(context.Background(), path); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "saved" {
+		t.Errorf("file content = %q, want %q", data, "saved")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Errorf("directory has %d entries, want only the saved file", len(entries))
+	}
+}
+
+func TestAttachmentSaveToLeavesNoPartialFile(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 500, Body: io.NopCloser(strings.NewReader("boom"))}, nil
+	})
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "out.txt")
+	att := c.Agents.Agent("agent-1").Thread("thread-1").Attachment("msg-1", ThreadMessageAttachment{
+		Name: "out.txt",
+		URL:  "https://files.example.com/out.txt",
+	})
+
+	if err := att.SaveTo(context.Background(), path); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 0 {
+		t.Errorf("directory has %d entries, want none", len(entries))
+	}
+}
+
+func TestExpiresAt(t *testing.T) {
+	exp := "2026-01-02T03:04:05Z"
+	att := ThreadMessageAttachment{ExpiryTime: &exp}
+	got, ok := att.ExpiresAt()
+	if !ok || !got.Equal(time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)) {
+		t.Errorf("ExpiresAt = %v, %v", got, ok)
+	}
+
+	if _, ok := (&ThreadMessageAttachment{}).ExpiresAt(); ok {
+		t.Error("attachment without expiry should report ok = false")
+	}
+	if _, ok := (&FileURL{ExpiryTime: "garbage"}).ExpiresAt(); ok {
+		t.Error("unparseable expiry should report ok = false")
+	}
+}
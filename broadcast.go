@@ -0,0 +1,234 @@
+package notionagents
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// SlowConsumerPolicy decides what a Broadcaster does when a subscriber's
+// buffer is full.
+type SlowConsumerPolicy int
+
+const (
+	// SlowConsumerBlock waits for the subscriber to make room. A blocked
+	// subscriber holds back every other subscriber.
+	SlowConsumerBlock SlowConsumerPolicy = iota
+	// SlowConsumerDrop skips the chunk for that subscriber and counts it in
+	// Subscription.Dropped.
+	SlowConsumerDrop
+	// SlowConsumerDisconnect closes the subscription; its Err then returns
+	// ErrSlowConsumer.
+	SlowConsumerDisconnect
+)
+
+// SubscribeOptions configures a Broadcaster subscription.
+type SubscribeOptions struct {
+	Buffer int                // Optional: channel buffer size, defaults to 16
+	Policy SlowConsumerPolicy // Optional: defaults to SlowConsumerBlock
+}
+
+const defaultSubscriptionBuffer = 16
+
+// Broadcaster fans the chunks of a single StreamReader out to multiple
+// subscribers, each with its own buffer and slow-consumer policy. The
+// underlying stream is read once, by Run.
+type Broadcaster struct {
+	reader *StreamReader
+
+	mu   sync.Mutex
+	subs []*Subscription
+	done bool
+	err  error
+}
+
+// NewBroadcaster returns a Broadcaster for reader. Add subscribers with
+// Subscribe, then call Run to start reading.
+func NewBroadcaster(reader *StreamReader) *Broadcaster {
+	return &Broadcaster{reader: reader}
+}
+
+// Subscription receives the chunks of a Broadcaster.
+type Subscription struct {
+	ch     chan StreamChunk
+	policy SlowConsumerPolicy
+	quit   chan struct{}
+	once   sync.Once
+
+	// Only the goroutine running the Broadcaster sends on and closes ch;
+	// mu guards the fields describing its state.
+	mu      sync.Mutex
+	closed  bool
+	dropped int
+	err     error
+}
+
+// Subscribe adds a subscriber. Subscribers added after Run has started miss
+// the chunks already sent, and subscribing after the stream has ended
+// returns a closed subscription.
+func (b *Broadcaster) Subscribe(opts *SubscribeOptions) *Subscription {
+	var o SubscribeOptions
+	if opts != nil {
+		o = *opts
+	}
+	if o.Buffer <= 0 {
+		o.Buffer = defaultSubscriptionBuffer
+	}
+
+	sub := &Subscription{
+		ch:     make(chan StreamChunk, o.Buffer),
+		policy: o.Policy,
+		quit:   make(chan struct{}),
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.done {
+		sub.close(b.err)
+		return sub
+	}
+	b.subs = append(b.subs, sub)
+	return sub
+}
+
+// Run reads the stream until it completes, fails or ctx is cancelled,
+// sending every chunk to each subscriber. It then closes all subscriptions
+// and the reader, and returns the accumulated thread info and the error
+// that ended the stream.
+func (b *Broadcaster) Run(ctx context.Context) (*ThreadInfo, error) {
+	defer b.reader.Close()
+
+	var err error
+	for {
+		chunk, nextErr := b.reader.Next()
+		if nextErr == io.EOF {
+			break
+		}
+		if nextErr != nil {
+			err = nextErr
+			break
+		}
+		if err = b.publish(ctx, chunk); err != nil {
+			break
+		}
+	}
+
+	b.mu.Lock()
+	b.done = true
+	b.err = err
+	subs := b.subs
+	b.subs = nil
+	b.mu.Unlock()
+
+	for _, sub := range subs {
+		sub.close(err)
+	}
+	return b.reader.ThreadInfo(), err
+}
+
+// publish delivers chunk to every subscriber according to its policy and
+// forgets subscribers that were closed.
+func (b *Broadcaster) publish(ctx context.Context, chunk StreamChunk) error {
+	b.mu.Lock()
+	subs := append([]*Subscription(nil), b.subs...)
+	b.mu.Unlock()
+
+	var err error
+	for _, sub := range subs {
+		if err = sub.send(ctx, chunk); err != nil {
+			break
+		}
+	}
+
+	b.mu.Lock()
+	kept := b.subs[:0]
+	for _, sub := range b.subs {
+		if !sub.isClosed() {
+			kept = append(kept, sub)
+		}
+	}
+	b.subs = kept
+	b.mu.Unlock()
+	return err
+}
+
+// send delivers chunk according to the subscription's policy. It only
+// returns an error if ctx is cancelled while blocked.
+func (s *Subscription) send(ctx context.Context, chunk StreamChunk) error {
+	select {
+	case <-s.quit:
+		s.close(nil)
+		return nil
+	default:
+	}
+
+	select {
+	case s.ch <- chunk:
+		return nil
+	default:
+	}
+
+	switch s.policy {
+	case SlowConsumerDrop:
+		s.mu.Lock()
+		s.dropped++
+		s.mu.Unlock()
+	case SlowConsumerDisconnect:
+		s.close(ErrSlowConsumer)
+	default:
+		select {
+		case s.ch <- chunk:
+		case <-s.quit:
+			s.close(nil)
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// close closes the subscription's channel with err as the reason.
+func (s *Subscription) close(err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+	s.closed = true
+	s.err = err
+	close(s.ch)
+}
+
+func (s *Subscription) isClosed() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.closed
+}
+
+// Chunks returns the channel of chunks. It is closed when the stream ends
+// or the subscriber is disconnected or unsubscribed.
+func (s *Subscription) Chunks() <-chan StreamChunk {
+	return s.ch
+}
+
+// Err returns why the subscription was closed: the error that ended the
+// stream, ErrSlowConsumer if the subscriber was disconnected, or nil.
+func (s *Subscription) Err() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.err
+}
+
+// Dropped returns the number of chunks skipped under SlowConsumerDrop.
+func (s *Subscription) Dropped() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.dropped
+}
+
+// Unsubscribe stops delivery to the subscriber without affecting the stream
+// or other subscribers. Its channel is closed when the Broadcaster next
+// publishes or finishes, so stop receiving from it after unsubscribing.
+func (s *Subscription) Unsubscribe() {
+	s.once.Do(func() { close(s.quit) })
+}
@@ -0,0 +1,144 @@
+package notionagents
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func broadcastChunks(n int) []StreamChunk {
+	chunks := []StreamChunk{{Type: "started", ThreadID: "t-1"}}
+	for i := 0; i < n; i++ {
+		chunks = append(chunks, StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: string(rune('a' + i))})
+	}
+	return append(chunks, StreamChunk{Type: "done"})
+}
+
+func TestBroadcasterFansOut(t *testing.T) {
+	b := NewBroadcaster(makeStreamReader(broadcastChunks(20)...))
+	subs := []*Subscription{
+		b.Subscribe(nil),
+		b.Subscribe(&SubscribeOptions{Buffer: 1}),
+		b.Subscribe(&SubscribeOptions{Buffer: 100}),
+	}
+
+	counts := make([]int, len(subs))
+	var wg sync.WaitGroup
+	for i, sub := range subs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range sub.Chunks() {
+				counts[i]++
+			}
+		}()
+	}
+
+	info, err := b.Run(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	wg.Wait()
+
+	for i, n := range counts {
+		if n != 22 {
+			t.Errorf("subscriber %d received %d chunks, want 22", i, n)
+		}
+		if err := subs[i].Err(); err != nil {
+			t.Errorf("subscriber %d Err = %v", i, err)
+		}
+	}
+	if info == nil || info.ThreadID != "t-1" {
+		t.Errorf("ThreadInfo = %+v", info)
+	}
+}
+
+func TestBroadcasterSlowConsumerPolicies(t *testing.T) {
+	b := NewBroadcaster(makeStreamReader(broadcastChunks(5)...))
+	drop := b.Subscribe(&SubscribeOptions{Buffer: 2, Policy: SlowConsumerDrop})
+	disconnect := b.Subscribe(&SubscribeOptions{Buffer: 2, Policy: SlowConsumerDisconnect})
+	steady := b.Subscribe(&SubscribeOptions{Buffer: 100})
+
+	if _, err := b.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if n := drain(drop); n != 2 {
+		t.Errorf("drop subscriber received %d chunks, want 2", n)
+	}
+	if drop.Dropped() != 5 {
+		t.Errorf("Dropped = %d, want 5", drop.Dropped())
+	}
+	if n := drain(disconnect); n != 2 {
+		t.Errorf("disconnected subscriber received %d chunks, want 2", n)
+	}
+	if !errors.Is(disconnect.Err(), ErrSlowConsumer) {
+		t.Errorf("Err = %v, want ErrSlowConsumer", disconnect.Err())
+	}
+	if n := drain(steady); n != 7 {
+		t.Errorf("steady subscriber received %d chunks, want 7", n)
+	}
+}
+
+func TestBroadcasterStreamError(t *testing.T) {
+	b := NewBroadcaster(makeStreamReader(
+		StreamChunk{Type: "started", ThreadID: "t-1"},
+		StreamChunk{Type: "error", Code: "internal_error", Message: "boom"},
+	))
+	sub := b.Subscribe(nil)
+
+	_, err := b.Run(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	drain(sub)
+	if sub.Err() != err {
+		t.Errorf("subscriber Err = %v, want %v", sub.Err(), err)
+	}
+
+	late := b.Subscribe(nil)
+	if _, ok := <-late.Chunks(); ok || late.Err() != err {
+		t.Errorf("late subscription should be closed with the stream error")
+	}
+}
+
+func TestBroadcasterUnsubscribe(t *testing.T) {
+	b := NewBroadcaster(makeStreamReader(broadcastChunks(5)...))
+	gone := b.Subscribe(&SubscribeOptions{Buffer: 1})
+	steady := b.Subscribe(&SubscribeOptions{Buffer: 100})
+	gone.Unsubscribe()
+
+	if _, err := b.Run(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if n := drain(gone); n != 0 {
+		t.Errorf("unsubscribed subscriber received %d chunks", n)
+	}
+	if n := drain(steady); n != 7 {
+		t.Errorf("steady subscriber received %d chunks, want 7", n)
+	}
+}
+
+func TestBroadcasterBlockedSubscriberContextCancel(t *testing.T) {
+	b := NewBroadcaster(makeStreamReader(broadcastChunks(5)...))
+	sub := b.Subscribe(&SubscribeOptions{Buffer: 1})
+
+	// Nothing reads from sub, so Run blocks once its buffer is full.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := b.Run(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if n := drain(sub); n != 1 {
+		t.Errorf("subscriber received %d chunks, want 1", n)
+	}
+}
+
+func drain(sub *Subscription) int {
+	n := 0
+	for range sub.Chunks() {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,133 @@
+package notionagents
+
+import (
+	"context"
+	"io"
+	"sync"
+)
+
+// ChatStreamHandle is a streaming chat consumed through a channel. It is
+// returned by Agent.ChatStream.
+//
+// The stream is read on a background goroutine that always closes the
+// response body and exits: when the stream completes or fails, when Wait or
+// Close is called, or when the context is cancelled.
+type ChatStreamHandle struct {
+	chunks  chan StreamChunk
+	discard chan struct{} // closed by Wait to drop unread chunks
+	stop    chan struct{} // closed by Close to abort the stream
+	done    chan struct{} // closed when the goroutine exits
+	cancel  context.CancelFunc
+
+	waitOnce sync.Once
+	stopOnce sync.Once
+
+	info *ThreadInfo
+	err  error
+}
+
+// ChatStream opens a streaming chat read on a background goroutine. Receive
+// chunks from Chunks, then call Wait for the thread info and final error.
+// Errors opening the stream are also reported by Wait.
+func (a *Agent) ChatStream(ctx context.Context, params ChatStreamParams) *ChatStreamHandle {
+	ctx, cancel := context.WithCancel(ctx)
+	h := &ChatStreamHandle{
+		chunks:  make(chan StreamChunk),
+		discard: make(chan struct{}),
+		stop:    make(chan struct{}),
+		done:    make(chan struct{}),
+		cancel:  cancel,
+	}
+	go h.run(ctx, a, params)
+	return h
+}
+
+func (h *ChatStreamHandle) run(ctx context.Context, a *Agent, params ChatStreamParams) {
+	defer close(h.done)
+	defer close(h.chunks)
+	defer h.cancel()
+
+	reader, err := a.Stream(ctx, params)
+	if err != nil {
+		h.err = err
+		return
+	}
+	defer reader.Close()
+
+	// Close and context cancellation may happen while Next is blocked
+	// reading the body; closing the body is what unblocks it.
+	finished := make(chan struct{})
+	defer close(finished)
+	go func() {
+		select {
+		case <-ctx.Done():
+			reader.resp.Body.Close()
+		case <-finished:
+		}
+	}()
+
+	defer func() { h.info = reader.ThreadInfo() }()
+
+	for {
+		chunk, err := reader.Next()
+		if err == io.EOF {
+			return
+		}
+		if err != nil {
+			h.err = h.stopErr(ctx, err)
+			return
+		}
+
+		select {
+		case h.chunks <- chunk:
+		case <-h.discard:
+		case <-h.stop:
+			h.err = h.stopErr(ctx, nil)
+			return
+		case <-ctx.Done():
+			h.err = h.stopErr(ctx, ctx.Err())
+			return
+		}
+	}
+}
+
+// stopErr replaces err with the reason the stream was stopped, if any.
+func (h *ChatStreamHandle) stopErr(ctx context.Context, err error) error {
+	select {
+	case <-h.stop:
+		return &StreamError{Msg: "stream closed before completion", Code: "stream_closed"}
+	default:
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// Chunks returns the channel of stream chunks. It is closed when the stream
+// ends for any reason.
+func (h *ChatStreamHandle) Chunks() <-chan StreamChunk {
+	return h.chunks
+}
+
+// Wait waits for the stream to finish and returns the accumulated thread
+// info and the error that ended it, if any. Chunks not yet received from
+// Chunks are discarded. The thread info may be partial or nil when the
+// error is non-nil.
+func (h *ChatStreamHandle) Wait() (*ThreadInfo, error) {
+	h.waitOnce.Do(func() { close(h.discard) })
+	<-h.done
+	return h.info, h.err
+}
+
+// Close aborts the stream if it is still running and waits for the
+// background goroutine to exit. A subsequent Wait returns an error matching
+// ErrStreamClosed unless the stream had already finished.
+func (h *ChatStreamHandle) Close() error {
+	h.stopOnce.Do(func() {
+		close(h.stop)
+		h.cancel()
+	})
+	<-h.done
+	return nil
+}
@@ -0,0 +1,169 @@
+package notionagents
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// trackedBody records whether it was closed.
+type trackedBody struct {
+	io.ReadCloser
+	closed atomic.Bool
+}
+
+func (b *trackedBody) Close() error {
+	b.closed.Store(true)
+	return b.ReadCloser.Close()
+}
+
+func chatStreamClient(body func() io.ReadCloser) *Client {
+	return mockClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: body()}, nil
+	})
+}
+
+func TestChatStreamHandle(t *testing.T) {
+	body := &trackedBody{ReadCloser: ndjsonResponse(
+		StreamChunk{Type: "started", ThreadID: "t-1", AgentID: "a-1"},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Hi"},
+		StreamChunk{Type: "done"},
+	).Body}
+	c := chatStreamClient(func() io.ReadCloser { return body })
+
+	h := c.Agents.Agent("a-1").ChatStream(context.Background(), ChatStreamParams{Message: "hi"})
+	var types []string
+	for chunk := range h.Chunks() {
+		types = append(types, chunk.Type)
+	}
+	info, err := h.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(types, ",") != "started,message,done" {
+		t.Errorf("chunk types = %v", types)
+	}
+	if info == nil || info.ThreadID != "t-1" || len(info.Messages) != 1 {
+		t.Errorf("ThreadInfo = %+v", info)
+	}
+	if !body.closed.Load() {
+		t.Error("response body was not closed")
+	}
+}
+
+func TestChatStreamHandleWaitDiscardsUnreadChunks(t *testing.T) {
+	chunks := []StreamChunk{{Type: "started", ThreadID: "t-1"}}
+	for i := 0; i < 50; i++ {
+		chunks = append(chunks, StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: strings.Repeat("a", i+1)})
+	}
+	chunks = append(chunks, StreamChunk{Type: "done"})
+	c := chatStreamClient(func() io.ReadCloser { return ndjsonResponse(chunks...).Body })
+
+	h := c.Agents.Agent("a-1").ChatStream(context.Background(), ChatStreamParams{Message: "hi"})
+	<-h.Chunks()
+
+	info, err := h.Wait()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(info.Messages) != 1 || len(info.Messages[0].Content) != 50 {
+		t.Errorf("ThreadInfo = %+v, want the complete message", info)
+	}
+	for range h.Chunks() {
+	}
+}
+
+func TestChatStreamHandleCloseUnblocksStalledStream(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	body := &trackedBody{ReadCloser: pr}
+	c := chatStreamClient(func() io.ReadCloser { return body })
+	go pw.Write([]byte(mustJSON(StreamChunk{Type: "started", ThreadID: "t-1"}) + "\n"))
+
+	h := c.Agents.Agent("a-1").ChatStream(context.Background(), ChatStreamParams{Message: "hi"})
+	if chunk := <-h.Chunks(); chunk.Type != "started" {
+		t.Fatalf("first chunk = %q, want started", chunk.Type)
+	}
+
+	closed := make(chan struct{})
+	go func() {
+		h.Close()
+		close(closed)
+	}()
+	select {
+	case <-closed:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not return while the stream was stalled")
+	}
+
+	_, err := h.Wait()
+	if !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("err = %v, want ErrStreamClosed", err)
+	}
+	if !body.closed.Load() {
+		t.Error("response body was not closed")
+	}
+	if _, ok := <-h.Chunks(); ok {
+		t.Error("Chunks channel was not closed")
+	}
+}
+
+func TestChatStreamHandleAbandonedConsumer(t *testing.T) {
+	body := &trackedBody{ReadCloser: ndjsonResponse(
+		StreamChunk{Type: "started", ThreadID: "t-1"},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Hi"},
+		StreamChunk{Type: "done"},
+	).Body}
+	c := chatStreamClient(func() io.ReadCloser { return body })
+
+	h := c.Agents.Agent("a-1").ChatStream(context.Background(), ChatStreamParams{Message: "hi"})
+	<-h.Chunks()
+	if err := h.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if !body.closed.Load() {
+		t.Error("response body was not closed")
+	}
+}
+
+func TestChatStreamHandleContextCancel(t *testing.T) {
+	pr, pw := io.Pipe()
+	defer pw.Close()
+	c := chatStreamClient(func() io.ReadCloser { return pr })
+	go pw.Write([]byte(mustJSON(StreamChunk{Type: "started", ThreadID: "t-1"}) + "\n"))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := c.Agents.Agent("a-1").ChatStream(ctx, ChatStreamParams{Message: "hi"})
+	<-h.Chunks()
+	// The goroutine is now blocked reading the stalled body.
+	cancel()
+
+	_, err := h.Wait()
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestChatStreamHandleOpenError(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(401, map[string]string{"code": "unauthorized", "message": "bad token"}), nil
+	})
+
+	h := c.Agents.Agent("a-1").ChatStream(context.Background(), ChatStreamParams{Message: "hi"})
+	if _, ok := <-h.Chunks(); ok {
+		t.Error("expected no chunks")
+	}
+	info, err := h.Wait()
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Errorf("err = %v, want ErrUnauthorized", err)
+	}
+	if info != nil {
+		t.Errorf("info = %+v, want nil", info)
+	}
+	h.Close()
+}
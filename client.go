@@ -4,10 +4,13 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"log/slog"
 	"net/http"
 	"strings"
+	"time"
 )
 
 // Client is the Notion Agents API client.
@@ -16,7 +19,13 @@ type Client struct {
 	baseURL       string
 	notionVersion string
 	httpClient    *http.Client
+	retry         *RetryPolicy
+	logger        *slog.Logger
+	limiter       *rateLimiter
+	maxLineSize   int
+	transport     Handler
 	Agents        *AgentOperations
+	FileUploads   *FileUploadOperations
 }
 
 // ClientOptions configures a new Client.
@@ -25,6 +34,16 @@ type ClientOptions struct {
 	BaseURL       string       // Optional: defaults to DefaultBaseURL
 	NotionVersion string       // Optional: defaults to DefaultVersion
 	HTTPClient    *http.Client // Optional: custom HTTP client
+	RetryPolicy   *RetryPolicy // Optional: retry failed requests, disabled when nil
+	Logger        *slog.Logger // Optional: structured request and stream logging, disabled when nil
+	Tracer        Tracer       // Optional: creates a span per API call, disabled when nil
+	Middleware    []Middleware // Optional: wraps every request, first is outermost
+	RateLimit     *RateLimit   // Optional: client-side request rate limit, disabled when nil
+
+	// MaxStreamLineSize limits the size of a single NDJSON line in
+	// streaming responses. Zero uses DefaultMaxStreamLineSize, negative
+	// means unlimited.
+	MaxStreamLineSize int
 }
 
 // NewClient creates a new Notion Agents client.
@@ -44,8 +63,21 @@ func NewClient(opts ClientOptions) *Client {
 		baseURL:       strings.TrimRight(opts.BaseURL, "/"),
 		notionVersion: opts.NotionVersion,
 		httpClient:    opts.HTTPClient,
+		retry:         opts.RetryPolicy,
+		logger:        opts.Logger,
+		limiter:       newRateLimiter(opts.RateLimit),
+		maxLineSize:   opts.MaxStreamLineSize,
 	}
+
+	var middleware []Middleware
+	if opts.Tracer != nil {
+		middleware = append(middleware, TracingMiddleware(opts.Tracer))
+	}
+	middleware = append(middleware, opts.Middleware...)
+	c.transport = chain(c.send, middleware...)
+
 	c.Agents = &AgentOperations{client: c}
+	c.FileUploads = &FileUploadOperations{client: c}
 	return c
 }
 
@@ -57,12 +89,23 @@ type apiError struct {
 	Message string `json:"message"`
 }
 
+// rawBody is a pre-encoded request body, such as multipart form data, that
+// doRequest sends as-is instead of encoding it as JSON.
+type rawBody struct {
+	contentType string
+	data        []byte
+}
+
 // doRequest executes an HTTP request with proper headers.
 func (c *Client) doRequest(ctx context.Context, method, path string, body interface{}) (*http.Response, error) {
 	url := c.baseURL + "/" + strings.TrimLeft(path, "/")
 
 	var bodyReader io.Reader
-	if body != nil {
+	contentType := "application/json"
+	if raw, ok := body.(rawBody); ok {
+		contentType = raw.contentType
+		bodyReader = bytes.NewReader(raw.data)
+	} else if body != nil {
 		data, err := json.Marshal(body)
 		if err != nil {
 			return nil, fmt.Errorf("marshaling request body: %w", err)
@@ -78,51 +121,121 @@ func (c *Client) doRequest(ctx context.Context, method, path string, body interf
 	req.Header.Set("Authorization", "Bearer "+c.auth)
 	req.Header.Set("Notion-Version", c.notionVersion)
 	if body != nil {
-		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
 	}
 
-	return c.httpClient.Do(req)
+	resp, err := c.transport(req)
+	if c.limiter != nil && err == nil {
+		c.limiter.observe(resp)
+	}
+	return resp, err
+}
+
+// send performs the HTTP round trip and logs the result.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := c.httpClient.Do(req)
+	c.logRequest(req, resp, err, time.Since(start))
+	return resp, err
 }
 
 // doJSON executes a request and unmarshals the JSON response.
+// Failed requests are retried according to the client's RetryPolicy.
 func (c *Client) doJSON(ctx context.Context, method, path string, body, result interface{}) error {
-	resp, err := c.doRequest(ctx, method, path, body)
-	if err != nil {
-		return err
-	}
-	defer resp.Body.Close()
+	maxAttempts := c.retry.maxAttempts()
+	ctx, call := withCallSpan(ctx)
+	defer call.end()
 
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return fmt.Errorf("reading response body: %w", err)
-	}
+	for attempt := 0; ; attempt++ {
+		resp, err := c.doRequest(withAttempt(ctx, attempt), method, path, body)
+		if err != nil {
+			return err
+		}
 
-	if resp.StatusCode >= 400 {
-		var apiErr apiError
-		if err := json.Unmarshal(respBody, &apiErr); err == nil {
-			if apiErr.Code == "object_not_found" {
-				if strings.Contains(apiErr.Message, "Could not find agent with ID:") {
-					return &AgentNotFoundError{AgentID: extractID(apiErr.Message, "agent")}
-				}
-				if strings.Contains(apiErr.Message, "Could not find thread with ID:") {
-					return &ThreadNotFoundError{ThreadID: extractID(apiErr.Message, "thread")}
+		respBody, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return fmt.Errorf("reading response body: %w", err)
+		}
+
+		if resp.StatusCode >= 400 {
+			apiErr := newAPIError(resp, respBody)
+			if attempt+1 < maxAttempts && c.retry.retryable(resp.StatusCode, errorCode(apiErr)) {
+				delay := c.retry.delay(attempt, parseRetryAfter(resp.Header.Get("Retry-After")))
+				if err := sleepContext(ctx, delay); err != nil {
+					return err
 				}
+				continue
 			}
-			return &NotionAgentsError{Msg: apiErr.Message, Code: apiErr.Code}
+			return apiErr
 		}
-		return &NotionAgentsError{
-			Msg:  fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(respBody)),
-			Code: "http_error",
+
+		if result != nil {
+			if err := json.Unmarshal(respBody, result); err != nil {
+				return fmt.Errorf("unmarshaling response: %w", err)
+			}
+		}
+
+		return nil
+	}
+}
+
+// newAPIError converts an error response into the matching SDK error type.
+func newAPIError(resp *http.Response, respBody []byte) error {
+	base := NotionAgentsError{
+		StatusCode: resp.StatusCode,
+		RequestID:  resp.Header.Get("x-request-id"),
+		Body:       respBody,
+	}
+
+	var apiErr apiError
+	if err := json.Unmarshal(respBody, &apiErr); err == nil {
+		if apiErr.Code == "object_not_found" {
+			if strings.Contains(apiErr.Message, "Could not find agent with ID:") {
+				return &AgentNotFoundError{AgentID: extractID(apiErr.Message, "agent")}
+			}
+			if strings.Contains(apiErr.Message, "Could not find thread with ID:") {
+				return &ThreadNotFoundError{ThreadID: extractID(apiErr.Message, "thread")}
+			}
 		}
+		base.Msg = apiErr.Message
+		base.Code = apiErr.Code
+	} else {
+		base.Msg = fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(respBody))
+		base.Code = "http_error"
 	}
 
-	if result != nil {
-		if err := json.Unmarshal(respBody, result); err != nil {
-			return fmt.Errorf("unmarshaling response: %w", err)
+	switch {
+	case base.Code == "rate_limited" || resp.StatusCode == http.StatusTooManyRequests:
+		return &RateLimitedError{
+			NotionAgentsError: base,
+			RetryAfter:        parseRetryAfter(resp.Header.Get("Retry-After")),
 		}
+	case base.Code == "unauthorized" || resp.StatusCode == http.StatusUnauthorized:
+		return &UnauthorizedError{NotionAgentsError: base}
+	case base.Code == "restricted_resource" || resp.StatusCode == http.StatusForbidden:
+		return &RestrictedResourceError{NotionAgentsError: base}
+	case base.Code == "conflict_error" || resp.StatusCode == http.StatusConflict:
+		return &ConflictError{NotionAgentsError: base}
+	case base.Code == "validation_error" || resp.StatusCode == http.StatusBadRequest:
+		return &ValidationError{NotionAgentsError: base}
 	}
+	return &base
+}
 
-	return nil
+// errorCode returns the Notion error code carried by err, if any.
+func errorCode(err error) string {
+	var nerr *NotionAgentsError
+	if errors.As(err, &nerr) {
+		return nerr.Code
+	}
+	return ""
 }
 
 // extractID is a helper to extract an ID from an error message.
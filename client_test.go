@@ -4,16 +4,26 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"testing"
+	"time"
 )
 
-func mockClient(fn func(req *http.Request) (*http.Response, error)) *Client {
-	return NewClient(ClientOptions{
-		Auth:       "test-token",
-		HTTPClient: &http.Client{Transport: roundTripFunc(fn)},
-	})
+// mockClient returns a client that sends requests to fn. Optional
+// ClientOptions configure the rest of the client; Auth defaults to a test
+// token.
+func mockClient(fn func(req *http.Request) (*http.Response, error), opts ...ClientOptions) *Client {
+	var o ClientOptions
+	if len(opts) > 0 {
+		o = opts[0]
+	}
+	if o.Auth == "" {
+		o.Auth = "test-token"
+	}
+	o.HTTPClient = &http.Client{Transport: roundTripFunc(fn)}
+	return NewClient(o)
 }
 
 type roundTripFunc func(req *http.Request) (*http.Response, error)
@@ -181,3 +191,113 @@ func jsonResponse(statusCode int, body interface{}) *http.Response {
 		Body:       io.NopCloser(bytes.NewReader(data)),
 	}
 }
+
+func TestDoJSONTypedErrors(t *testing.T) {
+	tests := []struct {
+		name     string
+		status   int
+		code     string
+		sentinel error
+	}{
+		{"rate limited", 429, "rate_limited", ErrRateLimited},
+		{"unauthorized", 401, "unauthorized", ErrUnauthorized},
+		{"restricted resource", 403, "restricted_resource", ErrRestrictedResource},
+		{"validation", 400, "validation_error", ErrValidation},
+		{"conflict", 409, "conflict_error", ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := mockClient(func(req *http.Request) (*http.Response, error) {
+				resp := jsonResponse(tt.status, map[string]interface{}{
+					"object":  "error",
+					"status":  tt.status,
+					"code":    tt.code,
+					"message": "failed",
+				})
+				resp.Header.Set("x-request-id", "req-123")
+				return resp, nil
+			})
+
+			err := c.doJSON(context.Background(), "GET", "/test", nil, nil)
+			if !errors.Is(err, tt.sentinel) {
+				t.Fatalf("errors.Is(%T, %v) = false, want true", err, tt.sentinel)
+			}
+
+			var nerr *NotionAgentsError
+			if !errors.As(err, &nerr) {
+				t.Fatalf("errors.As(%T, *NotionAgentsError) = false, want true", err)
+			}
+			if nerr.StatusCode != tt.status {
+				t.Errorf("StatusCode = %d, want %d", nerr.StatusCode, tt.status)
+			}
+			if nerr.Code != tt.code {
+				t.Errorf("Code = %q, want %q", nerr.Code, tt.code)
+			}
+			if nerr.RequestID != "req-123" {
+				t.Errorf("RequestID = %q, want %q", nerr.RequestID, "req-123")
+			}
+			if len(nerr.Body) == 0 {
+				t.Error("Body should contain the raw response body")
+			}
+		})
+	}
+}
+
+func TestDoJSONRateLimitedRetryAfter(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		resp := jsonResponse(429, map[string]interface{}{
+			"object":  "error",
+			"status":  429,
+			"code":    "rate_limited",
+			"message": "slow down",
+		})
+		resp.Header.Set("Retry-After", "7")
+		return resp, nil
+	})
+
+	err := c.doJSON(context.Background(), "GET", "/test", nil, nil)
+	var rle *RateLimitedError
+	if !errors.As(err, &rle) {
+		t.Fatalf("expected *RateLimitedError, got %T", err)
+	}
+	if rle.RetryAfter != 7*time.Second {
+		t.Errorf("RetryAfter = %v, want 7s", rle.RetryAfter)
+	}
+}
+
+func TestDoJSONNonJSONErrorBody(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: 502,
+			Header:     http.Header{},
+			Body:       io.NopCloser(bytes.NewReader([]byte("<html>bad gateway</html>"))),
+		}, nil
+	})
+
+	err := c.doJSON(context.Background(), "GET", "/test", nil, nil)
+	var nerr *NotionAgentsError
+	if !errors.As(err, &nerr) {
+		t.Fatalf("expected *NotionAgentsError, got %T", err)
+	}
+	if nerr.Code != "http_error" {
+		t.Errorf("Code = %q, want %q", nerr.Code, "http_error")
+	}
+	if nerr.StatusCode != 502 {
+		t.Errorf("StatusCode = %d, want 502", nerr.StatusCode)
+	}
+}
+
+func ndjsonResponse(chunks ...StreamChunk) *http.Response {
+	var buf bytes.Buffer
+	for _, c := range chunks {
+		data, _ := json.Marshal(c)
+		buf.Write(data)
+		buf.WriteByte('\n')
+	}
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/x-ndjson"}},
+		Body:       io.NopCloser(&buf),
+	}
+}
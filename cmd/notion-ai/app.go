@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"strings"
 
 	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
 	"github.com/charmbracelet/bubbles/spinner"
@@ -45,7 +46,6 @@ type appModel struct {
 	// Streaming
 	streaming    bool
 	streamCancel context.CancelFunc
-	lastContent  string
 	streamReader *notionagents.StreamReader
 
 	// Sub-models
@@ -424,7 +424,6 @@ func (m *appModel) handleChatInput(input string) (tea.Model, tea.Cmd) {
 	m.streaming = true
 	m.chat.Streaming = true
 	m.chat.StreamBuf = ""
-	m.lastContent = ""
 	m.statusBar.Streaming = true
 	m.statusBar.Error = ""
 
@@ -443,12 +442,9 @@ func (m *appModel) handleChatInput(input string) (tea.Model, tea.Cmd) {
 func (m appModel) handleStreamChunk(msg streamChunkMsg) (tea.Model, tea.Cmd) {
 	chunk := msg.chunk
 	if chunk.Type == "message" && chunk.Role == "agent" {
-		// Compute delta (content is cumulative)
-		if len(chunk.Content) > len(m.lastContent) {
-			delta := chunk.Content[len(m.lastContent):]
-			m.chat.StreamBuf += delta
-			m.lastContent = chunk.Content
-		}
+		// Rebuild from the per-message state rather than appending deltas,
+		// so interleaved and edited messages render correctly.
+		m.chat.StreamBuf = streamedAgentContent(m.streamReader)
 		m.chat.RefreshStreaming()
 	}
 	if chunk.Type == "started" && chunk.ThreadID != "" {
@@ -458,6 +454,22 @@ func (m appModel) handleStreamChunk(msg streamChunkMsg) (tea.Model, tea.Cmd) {
 	return m, readNextChunkCmd(m.streamReader)
 }
 
+// streamedAgentContent joins the content of the agent messages received so
+// far on the stream.
+func streamedAgentContent(reader *notionagents.StreamReader) string {
+	info := reader.ThreadInfo()
+	if info == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, msg := range info.Messages {
+		if msg.Role == notionagents.MessageRoleAgent {
+			b.WriteString(msg.Content)
+		}
+	}
+	return b.String()
+}
+
 func (m appModel) finalizeStream(msg streamDoneMsg) (tea.Model, tea.Cmd) {
 	m.streaming = false
 	m.chat.Streaming = false
@@ -477,7 +489,6 @@ func (m appModel) finalizeStream(msg streamDoneMsg) (tea.Model, tea.Cmd) {
 		m.chat.AppendMessage("agent", rendered)
 	}
 	m.chat.StreamBuf = ""
-	m.lastContent = ""
 
 	return m, nil
 }
@@ -23,6 +23,13 @@
 //	thread.Poll(ctx, nil)
 //	messages, _ := thread.ListMessages(ctx, nil)
 //
+// Or do the whole round-trip in one call with [Agent.Ask]:
+//
+//	result, _ := client.Agents.Agent(agentID).Ask(ctx, notionagents.ChatParams{
+//	    Message: "Hello!",
+//	}, nil)
+//	fmt.Println(result.Message.Content)
+//
 // # Streaming chat
 //
 // Stream responses in real time using the iterator-style [StreamReader]:
@@ -31,15 +38,16 @@
 //	    Message: "Summarize my week",
 //	})
 //	defer reader.Close()
-//	for {
-//	    chunk, err := reader.Next()
-//	    if err == io.EOF {
-//	        break
+//	for chunk, err := range reader.All() {
+//	    if err != nil {
+//	        return err
 //	    }
 //	    // handle chunk
 //	}
 //
 // Or use the channel-based [Agent.ChatStream] for concurrent consumption.
+// Set [ChatStreamParams].Resumable to fall back to polling the thread if the
+// connection drops mid-response.
 //
 // # Pagination
 //
@@ -1,17 +1,96 @@
 package notionagents
 
-import "fmt"
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Sentinel errors matched by the typed errors via errors.Is.
+var (
+	ErrAgentNotFound      = errors.New("notion agents: agent not found")
+	ErrThreadNotFound     = errors.New("notion agents: thread not found")
+	ErrPollingTimeout     = errors.New("notion agents: polling timed out")
+	ErrStreamClosed       = errors.New("notion agents: stream closed")
+	ErrStreamIdle         = errors.New("notion agents: stream idle timeout")
+	ErrSlowConsumer       = errors.New("notion agents: subscriber too slow")
+	ErrThreadFailed       = errors.New("notion agents: thread failed")
+	ErrRateLimited        = errors.New("notion agents: rate limited")
+	ErrUnauthorized       = errors.New("notion agents: unauthorized")
+	ErrRestrictedResource = errors.New("notion agents: restricted resource")
+	ErrValidation         = errors.New("notion agents: validation failed")
+	ErrConflict           = errors.New("notion agents: conflict")
+)
 
 // NotionAgentsError is the base error type for SDK errors.
+//
+// Errors returned from API responses also carry the HTTP status code, the
+// x-request-id response header and the raw response body.
 type NotionAgentsError struct {
-	Msg  string
-	Code string
+	Msg        string
+	Code       string
+	StatusCode int
+	RequestID  string
+	Body       []byte
 }
 
 func (e *NotionAgentsError) Error() string {
 	return fmt.Sprintf("notion agents error [%s]: %s", e.Code, e.Msg)
 }
 
+// RateLimitedError is returned when the API responds with 429 rate_limited.
+type RateLimitedError struct {
+	NotionAgentsError
+	RetryAfter time.Duration // Parsed Retry-After header, zero if absent
+}
+
+func (e *RateLimitedError) Unwrap() error { return &e.NotionAgentsError }
+
+func (e *RateLimitedError) Is(target error) bool { return target == ErrRateLimited }
+
+// UnauthorizedError is returned when the API token is missing or invalid.
+type UnauthorizedError struct {
+	NotionAgentsError
+}
+
+func (e *UnauthorizedError) Unwrap() error { return &e.NotionAgentsError }
+
+func (e *UnauthorizedError) Is(target error) bool { return target == ErrUnauthorized }
+
+// RestrictedResourceError is returned when the token lacks access to a resource.
+type RestrictedResourceError struct {
+	NotionAgentsError
+}
+
+func (e *RestrictedResourceError) Unwrap() error { return &e.NotionAgentsError }
+
+func (e *RestrictedResourceError) Is(target error) bool { return target == ErrRestrictedResource }
+
+// ValidationError is returned when the API rejects a request as invalid,
+// or when the SDK rejects invalid arguments before sending one.
+type ValidationError struct {
+	NotionAgentsError
+}
+
+func (e *ValidationError) Unwrap() error { return &e.NotionAgentsError }
+
+func (e *ValidationError) Is(target error) bool { return target == ErrValidation }
+
+// newValidationError returns a ValidationError for an invalid argument
+// caught by the SDK.
+func newValidationError(msg string) *ValidationError {
+	return &ValidationError{NotionAgentsError{Msg: msg, Code: "validation_error"}}
+}
+
+// ConflictError is returned when a request conflicts with concurrent changes.
+type ConflictError struct {
+	NotionAgentsError
+}
+
+func (e *ConflictError) Unwrap() error { return &e.NotionAgentsError }
+
+func (e *ConflictError) Is(target error) bool { return target == ErrConflict }
+
 // AgentNotFoundError is returned when an agent cannot be found.
 type AgentNotFoundError struct {
 	AgentID string
@@ -21,6 +100,8 @@ func (e *AgentNotFoundError) Error() string {
 	return fmt.Sprintf("agent not found: %s", e.AgentID)
 }
 
+func (e *AgentNotFoundError) Is(target error) bool { return target == ErrAgentNotFound }
+
 // ThreadNotFoundError is returned when a thread cannot be found.
 type ThreadNotFoundError struct {
 	ThreadID string
@@ -30,6 +111,8 @@ func (e *ThreadNotFoundError) Error() string {
 	return fmt.Sprintf("thread not found: %s", e.ThreadID)
 }
 
+func (e *ThreadNotFoundError) Is(target error) bool { return target == ErrThreadNotFound }
+
 // PollingTimeoutError is returned when thread polling exceeds max attempts.
 type PollingTimeoutError struct {
 	Attempts int
@@ -39,12 +122,67 @@ func (e *PollingTimeoutError) Error() string {
 	return fmt.Sprintf("polling timed out after %d attempts", e.Attempts)
 }
 
+func (e *PollingTimeoutError) Is(target error) bool { return target == ErrPollingTimeout }
+
+// ThreadFailedError is returned when a thread finishes with ThreadStatusFailed.
+//
+// When returned from strict polling, it also carries the last agent message
+// and the tool results that reported an error during the final turn.
+type ThreadFailedError struct {
+	ThreadID     string
+	Thread       ThreadListItem
+	AgentVersion *AgentVersion
+	LastMessage  *ThreadMessageItem
+	ToolErrors   []ToolResult
+}
+
+func (e *ThreadFailedError) Error() string {
+	msg := fmt.Sprintf("thread failed: %s", e.ThreadID)
+	if e.AgentVersion != nil {
+		msg += fmt.Sprintf(" (agent version %d)", e.AgentVersion.Number)
+	}
+	for _, result := range e.ToolErrors {
+		if result.Error == nil {
+			msg += fmt.Sprintf(": tool %s failed", result.ToolName)
+			continue
+		}
+		msg += fmt.Sprintf(": tool %s: %s", result.ToolName, *result.Error)
+	}
+	return msg
+}
+
+func (e *ThreadFailedError) Is(target error) bool { return target == ErrThreadFailed }
+
 // StreamError is returned for streaming-related errors.
+//
+// When the stream could not be opened because of an API error response,
+// Err holds the typed API error. A StreamError with Code "stream_closed"
+// matches ErrStreamClosed.
 type StreamError struct {
 	Msg  string
 	Code string
+	Err  error
 }
 
 func (e *StreamError) Error() string {
 	return fmt.Sprintf("stream error [%s]: %s", e.Code, e.Msg)
 }
+
+func (e *StreamError) Unwrap() error { return e.Err }
+
+func (e *StreamError) Is(target error) bool {
+	return target == ErrStreamClosed && e.Code == "stream_closed"
+}
+
+// StreamIdleError is returned when a stream with an idle timeout receives no
+// line within the timeout. It unwraps to a StreamError with Code
+// "stream_idle_timeout".
+type StreamIdleError struct {
+	StreamError
+	ThreadID string        // Thread the stream belongs to, if it had started
+	Timeout  time.Duration // Configured idle timeout
+}
+
+func (e *StreamIdleError) Unwrap() error { return &e.StreamError }
+
+func (e *StreamIdleError) Is(target error) bool { return target == ErrStreamIdle }
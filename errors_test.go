@@ -46,6 +46,21 @@ func TestStreamErrorMessage(t *testing.T) {
 	}
 }
 
+func TestThreadFailedErrorMessage(t *testing.T) {
+	msg := "timed out"
+	err := &ThreadFailedError{
+		ThreadID: "t-1",
+		ToolErrors: []ToolResult{
+			{ToolName: "search", Error: &msg},
+			{ToolName: "fetch"},
+		},
+	}
+	want := "thread failed: t-1: tool search: timed out: tool fetch failed"
+	if got := err.Error(); got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+}
+
 func TestErrorTypeAssertions(t *testing.T) {
 	tests := []struct {
 		name string
@@ -89,3 +104,80 @@ func TestIsThreadNotFound(t *testing.T) {
 		t.Error("isThreadNotFound should return false for other errors")
 	}
 }
+
+func TestTypedErrorsMatchSentinels(t *testing.T) {
+	base := NotionAgentsError{Msg: "failed", Code: "code", StatusCode: 400}
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+	}{
+		{"RateLimitedError", &RateLimitedError{NotionAgentsError: base}, ErrRateLimited},
+		{"UnauthorizedError", &UnauthorizedError{NotionAgentsError: base}, ErrUnauthorized},
+		{"RestrictedResourceError", &RestrictedResourceError{NotionAgentsError: base}, ErrRestrictedResource},
+		{"ValidationError", &ValidationError{NotionAgentsError: base}, ErrValidation},
+		{"ConflictError", &ConflictError{NotionAgentsError: base}, ErrConflict},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("calling api: %w", tt.err)
+			if !errors.Is(wrapped, tt.sentinel) {
+				t.Errorf("errors.Is should match %v", tt.sentinel)
+			}
+			if errors.Is(wrapped, ErrConflict) && tt.sentinel != ErrConflict {
+				t.Error("errors.Is should not match unrelated sentinels")
+			}
+			var nerr *NotionAgentsError
+			if !errors.As(wrapped, &nerr) || nerr.StatusCode != 400 {
+				t.Error("errors.As should find the embedded *NotionAgentsError")
+			}
+			if tt.err.Error() != base.Error() {
+				t.Errorf("Error() = %q, want %q", tt.err.Error(), base.Error())
+			}
+		})
+	}
+}
+
+func TestStreamErrorUnwrap(t *testing.T) {
+	err := &StreamError{Msg: "HTTP 401", Code: "http_error", Err: &UnauthorizedError{}}
+	if !errors.Is(err, ErrUnauthorized) {
+		t.Error("errors.Is should see through StreamError to the API error")
+	}
+}
+
+func TestErrorsMatchSentinels(t *testing.T) {
+	tests := []struct {
+		name     string
+		err      error
+		sentinel error
+	}{
+		{"AgentNotFoundError", &AgentNotFoundError{AgentID: "a"}, ErrAgentNotFound},
+		{"ThreadNotFoundError", &ThreadNotFoundError{ThreadID: "t"}, ErrThreadNotFound},
+		{"PollingTimeoutError", &PollingTimeoutError{Attempts: 1}, ErrPollingTimeout},
+		{"StreamError closed", &StreamError{Msg: "closed", Code: "stream_closed"}, ErrStreamClosed},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			wrapped := fmt.Errorf("middleware: %w", tt.err)
+			if !errors.Is(wrapped, tt.sentinel) {
+				t.Errorf("errors.Is should match %v", tt.sentinel)
+			}
+		})
+	}
+
+	if errors.Is(&StreamError{Code: "stream_read_error"}, ErrStreamClosed) {
+		t.Error("only stream_closed errors should match ErrStreamClosed")
+	}
+	if errors.Is(&AgentNotFoundError{}, ErrThreadNotFound) {
+		t.Error("AgentNotFoundError should not match ErrThreadNotFound")
+	}
+}
+
+func TestIsThreadNotFoundWrapped(t *testing.T) {
+	err := fmt.Errorf("wrapped: %w", &ThreadNotFoundError{ThreadID: "t"})
+	if !isThreadNotFound(err) {
+		t.Error("isThreadNotFound should see through wrapped errors")
+	}
+}
@@ -0,0 +1,161 @@
+package notionagents
+
+import (
+	"fmt"
+	"iter"
+)
+
+// StreamEvent is a typed event derived from the chunks of a streaming chat.
+// The concrete types are StreamStarted, MessageUpdated, ToolCallStarted,
+// ToolCallFinished, FollowUpsSuggested and StreamDone; switch on them with a
+// type switch.
+type StreamEvent interface {
+	streamEvent()
+}
+
+// StreamStarted is emitted when the server has created or resumed the thread.
+type StreamStarted struct {
+	ThreadID string
+	AgentID  string
+}
+
+// MessageUpdated is emitted each time a message is created or changed.
+// Message holds the accumulated message and Delta what this update changed.
+type MessageUpdated struct {
+	Message StreamMessage
+	Delta   StreamDelta
+}
+
+// ToolCallStarted is emitted the first time a tool call appears in a
+// message's content parts. ToolCallID falls back to "<message id>/<part
+// index>" when the server does not assign one.
+type ToolCallStarted struct {
+	MessageID  string
+	ToolCallID string
+	ToolName   string
+	Input      string
+}
+
+// ToolCallFinished is emitted once a tool call reports its results.
+type ToolCallFinished struct {
+	MessageID  string
+	ToolCallID string
+	ToolName   string
+	Results    []ToolResult
+}
+
+// FollowUpsSuggested is emitted when a message suggests follow-up actions.
+type FollowUpsSuggested struct {
+	MessageID string
+	FollowUps []FollowUp
+}
+
+// StreamDone is emitted when the stream completes. ThreadInfo holds the
+// accumulated messages.
+type StreamDone struct {
+	ThreadInfo *ThreadInfo
+}
+
+func (StreamStarted) streamEvent()      {}
+func (MessageUpdated) streamEvent()     {}
+func (ToolCallStarted) streamEvent()    {}
+func (ToolCallFinished) streamEvent()   {}
+func (FollowUpsSuggested) streamEvent() {}
+func (StreamDone) streamEvent()         {}
+
+// Events returns an iterator over typed events derived from the stream.
+// A message chunk yields a MessageUpdated followed by any tool call and
+// follow-up events its content parts introduce, each reported once.
+// Iteration stops after StreamDone or at the first error, and the response
+// body is closed if the loop exits early.
+func (r *StreamReader) Events() iter.Seq2[StreamEvent, error] {
+	return func(yield func(StreamEvent, error) bool) {
+		for chunk, err := range r.All() {
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, event := range r.chunkEvents(chunk) {
+				if !yield(event, nil) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// chunkEvents converts a chunk already processed by Next into events.
+func (r *StreamReader) chunkEvents(chunk StreamChunk) []StreamEvent {
+	switch chunk.Type {
+	case "started":
+		return []StreamEvent{StreamStarted{ThreadID: chunk.ThreadID, AgentID: chunk.AgentID}}
+
+	case "message":
+		msg := r.messages[chunk.ID]
+		if msg == nil || chunk.Delta == nil {
+			return nil
+		}
+		events := []StreamEvent{MessageUpdated{Message: *msg, Delta: *chunk.Delta}}
+		for i, part := range msg.ContentParts {
+			events = append(events, r.partEvents(chunk.ID, i, part)...)
+		}
+		return events
+
+	case "done":
+		return []StreamEvent{StreamDone{ThreadInfo: r.ThreadInfo()}}
+	}
+	return nil
+}
+
+// Progress recorded per content part so each part event is emitted once.
+const (
+	toolCallStarted = iota + 1
+	toolCallFinished
+)
+
+// partEvents reports tool calls the first time they are seen and again when
+// their results arrive, and follow-ups whenever new suggestions appear.
+func (r *StreamReader) partEvents(messageID string, index int, part AgentContentPart) []StreamEvent {
+	if r.partState == nil {
+		r.partState = make(map[string]int)
+	}
+	key := fmt.Sprintf("%s/%d", messageID, index)
+
+	switch part.Type {
+	case ContentPartToolCall:
+		id := key
+		if part.ToolCallID != nil {
+			id = *part.ToolCallID
+		}
+		var events []StreamEvent
+		state := r.partState[id]
+		if state < toolCallStarted {
+			state = toolCallStarted
+			events = append(events, ToolCallStarted{
+				MessageID:  messageID,
+				ToolCallID: id,
+				ToolName:   part.ToolName,
+				Input:      part.Input,
+			})
+		}
+		if state < toolCallFinished && len(part.Results) > 0 {
+			state = toolCallFinished
+			events = append(events, ToolCallFinished{
+				MessageID:  messageID,
+				ToolCallID: id,
+				ToolName:   part.ToolName,
+				Results:    part.Results,
+			})
+		}
+		r.partState[id] = state
+		return events
+
+	case ContentPartFollowUps:
+		if len(part.FollowUps) == 0 || len(part.FollowUps) == r.partState[key] {
+			return nil
+		}
+		r.partState[key] = len(part.FollowUps)
+		return []StreamEvent{FollowUpsSuggested{MessageID: messageID, FollowUps: part.FollowUps}}
+	}
+	return nil
+}
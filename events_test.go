@@ -0,0 +1,86 @@
+package notionagents
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestStreamReaderEvents(t *testing.T) {
+	callID := "call-1"
+	r := makeStreamReader(
+		StreamChunk{Type: "started", ThreadID: "t-1", AgentID: "a-1"},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Searching", ContentParts: []AgentContentPart{
+			{Type: ContentPartText, Text: "Searching"},
+			{Type: ContentPartToolCall, ToolCallID: &callID, ToolName: "search", Input: "q"},
+		}},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Searching...", ContentParts: []AgentContentPart{
+			{Type: ContentPartText, Text: "Searching..."},
+			{Type: ContentPartToolCall, ToolCallID: &callID, ToolName: "search", Input: "q"},
+		}},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Searching... done", ContentParts: []AgentContentPart{
+			{Type: ContentPartText, Text: "Searching... done"},
+			{Type: ContentPartToolCall, ToolCallID: &callID, ToolName: "search", Input: "q", Results: []ToolResult{{ID: "r-1"}}},
+			{Type: ContentPartFollowUps, FollowUps: []FollowUp{{Label: "More"}}},
+		}},
+		StreamChunk{Type: "done"},
+	)
+
+	var got []string
+	for event, err := range r.Events() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch e := event.(type) {
+		case StreamStarted:
+			got = append(got, "started:"+e.ThreadID)
+		case MessageUpdated:
+			got = append(got, fmt.Sprintf("message:%s:%q", e.Message.ID, e.Delta.Text))
+		case ToolCallStarted:
+			got = append(got, "tool_started:"+e.ToolName)
+		case ToolCallFinished:
+			got = append(got, fmt.Sprintf("tool_finished:%s:%d", e.ToolCallID, len(e.Results)))
+		case FollowUpsSuggested:
+			got = append(got, "follow_ups:"+e.FollowUps[0].Label)
+		case StreamDone:
+			got = append(got, fmt.Sprintf("done:%d", len(e.ThreadInfo.Messages)))
+		}
+	}
+
+	want := []string{
+		"started:t-1",
+		`message:msg-1:"Searching"`,
+		"tool_started:search",
+		`message:msg-1:"..."`,
+		`message:msg-1:" done"`,
+		"tool_finished:call-1:1",
+		"follow_ups:More",
+		"done:1",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("events =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestStreamReaderEventsError(t *testing.T) {
+	r := makeStreamReader(
+		StreamChunk{Type: "started", ThreadID: "t-1"},
+		StreamChunk{Type: "error", Code: "internal_error", Message: "boom"},
+	)
+
+	var events int
+	var err error
+	for _, err = range r.Events() {
+		if err != nil {
+			break
+		}
+		events++
+	}
+	var se *StreamError
+	if !errors.As(err, &se) || se.Code != "internal_error" {
+		t.Errorf("err = %v, want internal_error StreamError", err)
+	}
+	if events != 1 {
+		t.Errorf("events before error = %d, want 1", events)
+	}
+}
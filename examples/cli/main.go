@@ -9,6 +9,7 @@ import (
 	"os/signal"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
 )
@@ -79,7 +80,8 @@ func main() {
 			continue
 		}
 
-		var lastContent string
+		// The agent text shown so far
+		var printed string
 		for {
 			chunk, err := reader.Next()
 			if err == io.EOF {
@@ -91,11 +93,10 @@ func main() {
 			}
 
 			if chunk.Type == "message" && chunk.Role == "agent" {
-				// Print incremental content (delta since last)
-				if len(chunk.Content) > len(lastContent) {
-					fmt.Print(chunk.Content[len(lastContent):])
-					lastContent = chunk.Content
-				}
+				// Rebuild from every agent message so far, so interleaved
+				// and edited messages stay in order, and print only what
+				// changed.
+				printed = redraw(printed, agentText(reader.ThreadInfo()))
 			}
 		}
 		fmt.Println()
@@ -107,6 +108,41 @@ func main() {
 	}
 }
 
+// agentText joins the content of the agent messages in info.
+func agentText(info *notionagents.ThreadInfo) string {
+	if info == nil {
+		return ""
+	}
+	var b strings.Builder
+	for _, msg := range info.Messages {
+		if msg.Role == notionagents.MessageRoleAgent {
+			b.WriteString(msg.Content)
+		}
+	}
+	return b.String()
+}
+
+// redraw updates the printed text in place to show content. It erases the
+// printed text after the part that did not change and prints the new
+// ending. Changes that reach back past a line break are printed in full on
+// a new line.
+func redraw(printed, content string) string {
+	common := 0
+	for i, r := range printed {
+		if !strings.HasPrefix(content[i:], string(r)) {
+			break
+		}
+		common = i + utf8.RuneLen(r)
+	}
+	erased := printed[common:]
+	if strings.Contains(erased, "\n") {
+		fmt.Print("\n", content)
+		return content
+	}
+	fmt.Print(strings.Repeat("\b \b", utf8.RuneCountInString(erased)), content[common:])
+	return content
+}
+
 func selectAgent(ctx context.Context, client *notionagents.Client) (*notionagents.Agent, error) {
 	agents, err := notionagents.CollectAgents(ctx, client, nil)
 	if err != nil {
@@ -0,0 +1,332 @@
+package notionagents
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"net/textproto"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	// MaxSinglePartUploadSize is the largest file sent in a single part.
+	// Larger files are uploaded in multiple parts.
+	MaxSinglePartUploadSize = 20 << 20
+
+	// MinUploadPartSize is the smallest allowed size for every part of a
+	// multi-part upload except the last.
+	MinUploadPartSize = 5 << 20
+
+	// DefaultUploadPartSize is the default multi-part chunk size.
+	DefaultUploadPartSize = 10 << 20
+
+	// MaxUploadParts is the largest number of parts in a multi-part upload.
+	MaxUploadParts = 1000
+)
+
+// FileUploadOperations provides operations on file uploads.
+type FileUploadOperations struct {
+	client *Client
+}
+
+// Attachment returns a ChatAttachmentInput referencing this upload, ready to
+// use in ChatParams.Attachments or ChatStreamParams.Attachments.
+func (u *FileUpload) Attachment() ChatAttachmentInput {
+	return ChatAttachmentInput{FileUploadID: u.ID, Name: u.Filename}
+}
+
+// Create starts a new file upload.
+func (f *FileUploadOperations) Create(ctx context.Context, params FileUploadCreateParams) (*FileUpload, error) {
+	ctx = withOperation(ctx, Operation{Name: OpFileUploadsCreate})
+	var resp FileUpload
+	if err := f.client.doJSON(ctx, http.MethodPost, "v1/file_uploads", params, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Retrieve returns the current state of a file upload.
+func (f *FileUploadOperations) Retrieve(ctx context.Context, fileUploadID string) (*FileUpload, error) {
+	ctx = withOperation(ctx, Operation{Name: OpFileUploadsRetrieve})
+	var resp FileUpload
+	path := fmt.Sprintf("v1/file_uploads/%s", fileUploadID)
+	if err := f.client.doJSON(ctx, http.MethodGet, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Send uploads file contents for a pending upload. partNumber is 1-based for
+// multi-part uploads and must be zero for single-part uploads.
+func (f *FileUploadOperations) Send(ctx context.Context, fileUploadID, filename, contentType string, data []byte, partNumber int) (*FileUpload, error) {
+	body, err := multipartBody(filename, contentType, data, partNumber)
+	if err != nil {
+		return nil, err
+	}
+
+	ctx = withOperation(ctx, Operation{Name: OpFileUploadsSend})
+	var resp FileUpload
+	path := fmt.Sprintf("v1/file_uploads/%s/send", fileUploadID)
+	if err := f.client.doJSON(ctx, http.MethodPost, path, body, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Complete finishes a multi-part upload after all parts have been sent.
+func (f *FileUploadOperations) Complete(ctx context.Context, fileUploadID string) (*FileUpload, error) {
+	ctx = withOperation(ctx, Operation{Name: OpFileUploadsComplete})
+	var resp FileUpload
+	path := fmt.Sprintf("v1/file_uploads/%s/complete", fileUploadID)
+	if err := f.client.doJSON(ctx, http.MethodPost, path, nil, &resp); err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+// Upload creates a file upload and sends the contents of r, choosing a
+// single-part or multi-part upload based on its size. The returned upload's
+// Attachment method produces a value for ChatParams.Attachments.
+//
+// When params.Size is zero, the size is detected from readers that report
+// it (such as *os.File, *bytes.Reader and *strings.Reader). Readers of
+// unknown size are limited to MaxSinglePartUploadSize.
+func (f *FileUploadOperations) Upload(ctx context.Context, r io.Reader, params FileUploadParams) (*FileUpload, error) {
+	if params.Filename == "" {
+		return nil, newValidationError("Filename is required.")
+	}
+
+	size := params.Size
+	if size <= 0 {
+		size = readerSize(r)
+	}
+	if size > 0 && params.MaxSize > 0 && size > params.MaxSize {
+		return nil, fileTooLargeError(size, params.MaxSize)
+	}
+
+	br := bufio.NewReader(r)
+	contentType := params.ContentType
+	if contentType == "" {
+		contentType = detectContentType(params.Filename, br)
+	}
+
+	if size <= 0 {
+		// Unknown size: buffer up to the single-part limit.
+		data, err := io.ReadAll(io.LimitReader(br, MaxSinglePartUploadSize+1))
+		if err != nil {
+			return nil, fmt.Errorf("reading upload: %w", err)
+		}
+		if len(data) > MaxSinglePartUploadSize {
+			return nil, newValidationError(fmt.Sprintf("Size is required for uploads larger than %d bytes.", MaxSinglePartUploadSize))
+		}
+		if params.MaxSize > 0 && int64(len(data)) > params.MaxSize {
+			return nil, fileTooLargeError(int64(len(data)), params.MaxSize)
+		}
+		return f.uploadSinglePart(ctx, data, params.Filename, contentType, params.OnProgress)
+	}
+
+	if size <= MaxSinglePartUploadSize {
+		data, err := io.ReadAll(io.LimitReader(br, size))
+		if err != nil {
+			return nil, fmt.Errorf("reading upload: %w", err)
+		}
+		return f.uploadSinglePart(ctx, data, params.Filename, contentType, params.OnProgress)
+	}
+
+	return f.uploadMultiPart(ctx, br, size, params.Filename, contentType, params)
+}
+
+// UploadFile uploads the file at path, using its base name as the filename.
+func (f *FileUploadOperations) UploadFile(ctx context.Context, path string) (*FileUpload, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("opening upload: %w", err)
+	}
+	defer file.Close()
+
+	return f.Upload(ctx, file, FileUploadParams{Filename: filepath.Base(path)})
+}
+
+// uploadAttachments uploads each file and returns the attachments that
+// reference them, in the same order as paths.
+func (f *FileUploadOperations) uploadAttachments(ctx context.Context, paths []string) ([]ChatAttachmentInput, error) {
+	attachments := make([]ChatAttachmentInput, 0, len(paths))
+	for _, path := range paths {
+		upload, err := f.UploadFile(ctx, path)
+		if err != nil {
+			return nil, fmt.Errorf("uploading %s: %w", path, err)
+		}
+		attachments = append(attachments, ChatAttachmentInput{
+			FileUploadID: upload.ID,
+			Name:         filepath.Base(path),
+		})
+	}
+	return attachments, nil
+}
+
+func (f *FileUploadOperations) uploadSinglePart(ctx context.Context, data []byte, filename, contentType string, onProgress func(sent, total int64)) (*FileUpload, error) {
+	upload, err := f.Create(ctx, FileUploadCreateParams{
+		Mode:        FileUploadModeSinglePart,
+		Filename:    filename,
+		ContentType: contentType,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	upload, err = f.Send(ctx, upload.ID, filename, contentType, data, 0)
+	if err != nil {
+		return nil, err
+	}
+	if onProgress != nil {
+		onProgress(int64(len(data)), int64(len(data)))
+	}
+	return upload, nil
+}
+
+func (f *FileUploadOperations) uploadMultiPart(ctx context.Context, r io.Reader, size int64, filename, contentType string, params FileUploadParams) (*FileUpload, error) {
+	partSize := params.PartSize
+	if partSize <= 0 {
+		partSize = DefaultUploadPartSize
+	}
+	if partSize < MinUploadPartSize || partSize > MaxSinglePartUploadSize {
+		return nil, newValidationError(fmt.Sprintf("PartSize must be between %d and %d bytes.", MinUploadPartSize, MaxSinglePartUploadSize))
+	}
+	parts := int((size + partSize - 1) / partSize)
+	if parts > MaxUploadParts {
+		return nil, newValidationError(fmt.Sprintf("File of %d bytes needs %d parts, more than the maximum of %d.", size, parts, MaxUploadParts))
+	}
+
+	upload, err := f.Create(ctx, FileUploadCreateParams{
+		Mode:          FileUploadModeMultiPart,
+		Filename:      filename,
+		ContentType:   contentType,
+		NumberOfParts: parts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	buf := make([]byte, partSize)
+	var sent int64
+	for part := 1; part <= parts; part++ {
+		n, err := io.ReadFull(r, buf[:min(partSize, size-sent)])
+		if err != nil {
+			if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+				return nil, fmt.Errorf("reading upload part %d: file shorter than declared size %d", part, size)
+			}
+			return nil, fmt.Errorf("reading upload part %d: %w", part, err)
+		}
+		if _, err := f.Send(ctx, upload.ID, filename, contentType, buf[:n], part); err != nil {
+			return nil, err
+		}
+		sent += int64(n)
+		if params.OnProgress != nil {
+			params.OnProgress(sent, size)
+		}
+	}
+
+	return f.Complete(ctx, upload.ID)
+}
+
+// multipartBody encodes file data as the multipart form expected by the
+// send endpoint.
+func multipartBody(filename, contentType string, data []byte, partNumber int) (rawBody, error) {
+	var buf bytes.Buffer
+	w := multipart.NewWriter(&buf)
+
+	if partNumber > 0 {
+		if err := w.WriteField("part_number", strconv.Itoa(partNumber)); err != nil {
+			return rawBody{}, fmt.Errorf("encoding upload: %w", err)
+		}
+	}
+
+	header := make(textproto.MIMEHeader)
+	header.Set("Content-Disposition", mime.FormatMediaType("form-data", map[string]string{
+		"name":     "file",
+		"filename": filename,
+	}))
+	if contentType != "" {
+		header.Set("Content-Type", contentType)
+	}
+	part, err := w.CreatePart(header)
+	if err != nil {
+		return rawBody{}, fmt.Errorf("encoding upload: %w", err)
+	}
+	if _, err := part.Write(data); err != nil {
+		return rawBody{}, fmt.Errorf("encoding upload: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return rawBody{}, fmt.Errorf("encoding upload: %w", err)
+	}
+
+	return rawBody{contentType: w.FormDataContentType(), data: buf.Bytes()}, nil
+}
+
+// readerSize returns the number of bytes remaining in r when r can report
+// it, or zero otherwise.
+func readerSize(r io.Reader) int64 {
+	switch v := r.(type) {
+	case interface{ Len() int }:
+		return int64(v.Len())
+	case interface{ Stat() (fs.FileInfo, error) }:
+		info, err := v.Stat()
+		if err != nil || !info.Mode().IsRegular() {
+			return 0
+		}
+		size := info.Size()
+		if s, ok := r.(io.Seeker); ok {
+			if offset, err := s.Seek(0, io.SeekCurrent); err == nil {
+				size -= offset
+			}
+		}
+		return size
+	}
+	return 0
+}
+
+// uploadContentTypes covers common upload extensions that are missing from
+// the built-in mime table on systems without a mime.types file.
+var uploadContentTypes = map[string]string{
+	".csv":  "text/csv",
+	".tsv":  "text/tab-separated-values",
+	".md":   "text/markdown",
+	".txt":  "text/plain",
+	".docx": "application/vnd.openxmlformats-officedocument.wordprocessingml.document",
+	".xlsx": "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet",
+	".pptx": "application/vnd.openxmlformats-officedocument.presentationml.presentation",
+}
+
+// detectContentType infers a media type from the filename extension, falling
+// back to sniffing the first bytes of the content.
+func detectContentType(filename string, br *bufio.Reader) string {
+	ext := strings.ToLower(filepath.Ext(filename))
+	if byExt := mime.TypeByExtension(ext); byExt != "" {
+		if mediaType, _, err := mime.ParseMediaType(byExt); err == nil {
+			return mediaType
+		}
+	}
+	if known, ok := uploadContentTypes[ext]; ok {
+		return known
+	}
+	head, _ := br.Peek(512)
+	sniffed := http.DetectContentType(head)
+	if i := strings.Index(sniffed, ";"); i >= 0 {
+		sniffed = sniffed[:i]
+	}
+	return sniffed
+}
+
+func fileTooLargeError(size, maxSize int64) error {
+	return newValidationError(fmt.Sprintf("File of %d bytes exceeds the maximum size of %d bytes.", size, maxSize))
+}
@@ -0,0 +1,185 @@
+package notionagents
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+type sentPart struct {
+	partNumber  string
+	filename    string
+	contentType string
+	size        int
+}
+
+// uploadServer returns a mock transport that implements the file upload
+// endpoints and records what was sent.
+func uploadServer(t *testing.T, created *FileUploadCreateParams, parts *[]sentPart, completed *bool) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/v1/file_uploads":
+			data, _ := io.ReadAll(req.Body)
+			if err := json.Unmarshal(data, created); err != nil {
+				t.Fatalf("create body: %v", err)
+			}
+			return jsonResponse(200, FileUpload{Object: "file_upload", ID: "fu-1", Status: FileUploadStatusPending, Filename: created.Filename}), nil
+
+		case strings.HasSuffix(req.URL.Path, "/send"):
+			mediaType, mparams, err := mime.ParseMediaType(req.Header.Get("Content-Type"))
+			if err != nil || mediaType != "multipart/form-data" {
+				t.Fatalf("Content-Type = %q, want multipart/form-data", req.Header.Get("Content-Type"))
+			}
+			form, err := multipart.NewReader(req.Body, mparams["boundary"]).ReadForm(64 << 20)
+			if err != nil {
+				t.Fatalf("reading form: %v", err)
+			}
+			fh := form.File["file"][0]
+			var p sentPart
+			if v := form.Value["part_number"]; len(v) > 0 {
+				p.partNumber = v[0]
+			}
+			p.filename = fh.Filename
+			p.contentType = fh.Header.Get("Content-Type")
+			p.size = int(fh.Size)
+			*parts = append(*parts, p)
+			return jsonResponse(200, FileUpload{Object: "file_upload", ID: "fu-1", Status: FileUploadStatusUploaded, Filename: created.Filename}), nil
+
+		case strings.HasSuffix(req.URL.Path, "/complete"):
+			*completed = true
+			return jsonResponse(200, FileUpload{Object: "file_upload", ID: "fu-1", Status: FileUploadStatusUploaded, Filename: created.Filename}), nil
+		}
+		t.Fatalf("unexpected request %s %s", req.Method, req.URL.Path)
+		return nil, nil
+	}
+}
+
+func TestFileUploadSinglePart(t *testing.T) {
+	var created FileUploadCreateParams
+	var parts []sentPart
+	var completed bool
+	c := mockClient(uploadServer(t, &created, &parts, &completed))
+
+	var progress []int64
+	upload, err := c.FileUploads.Upload(context.Background(), strings.NewReader("a,b\n1,2\n"), FileUploadParams{
+		Filename:   "report.csv",
+		OnProgress: func(sent, total int64) { progress = append(progress, sent) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created.Mode != FileUploadModeSinglePart {
+		t.Errorf("mode = %q, want %q", created.Mode, FileUploadModeSinglePart)
+	}
+	if created.ContentType != "text/csv" {
+		t.Errorf("content type = %q, want text/csv", created.ContentType)
+	}
+	if len(parts) != 1 || parts[0].partNumber != "" || parts[0].size != 8 {
+		t.Errorf("parts = %+v, want one unnumbered 8-byte part", parts)
+	}
+	if parts[0].filename != "report.csv" {
+		t.Errorf("filename = %q, want report.csv", parts[0].filename)
+	}
+	if completed {
+		t.Error("single-part uploads should not be completed")
+	}
+	if len(progress) != 1 || progress[0] != 8 {
+		t.Errorf("progress = %v, want [8]", progress)
+	}
+
+	att := upload.Attachment()
+	if att.FileUploadID != "fu-1" || att.Name != "report.csv" {
+		t.Errorf("attachment = %+v, want fu-1 report.csv", att)
+	}
+}
+
+func TestFileUploadMultiPart(t *testing.T) {
+	var created FileUploadCreateParams
+	var parts []sentPart
+	var completed bool
+	c := mockClient(uploadServer(t, &created, &parts, &completed))
+
+	size := MaxSinglePartUploadSize + MinUploadPartSize + 10
+	data := bytes.Repeat([]byte{'x'}, size)
+
+	var progress []int64
+	_, err := c.FileUploads.Upload(context.Background(), bytes.NewReader(data), FileUploadParams{
+		Filename:    "big.bin",
+		ContentType: "application/octet-stream",
+		PartSize:    MaxSinglePartUploadSize,
+		OnProgress:  func(sent, total int64) { progress = append(progress, sent) },
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if created.Mode != FileUploadModeMultiPart || created.NumberOfParts != 2 {
+		t.Errorf("create = %+v, want multi_part with 2 parts", created)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("parts = %d, want 2", len(parts))
+	}
+	if parts[0].partNumber != "1" || parts[0].size != MaxSinglePartUploadSize {
+		t.Errorf("part 1 = %+v", parts[0])
+	}
+	if parts[1].partNumber != "2" || parts[1].size != MinUploadPartSize+10 {
+		t.Errorf("part 2 = %+v", parts[1])
+	}
+	if !completed {
+		t.Error("multi-part upload should be completed")
+	}
+	if len(progress) != 2 || progress[1] != int64(size) {
+		t.Errorf("progress = %v, want two updates ending at %d", progress, size)
+	}
+}
+
+func TestFileUploadValidation(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("no request should be sent")
+		return nil, nil
+	})
+
+	tests := []struct {
+		name   string
+		r      io.Reader
+		params FileUploadParams
+	}{
+		{"missing filename", strings.NewReader("x"), FileUploadParams{}},
+		{"exceeds max size", strings.NewReader("hello"), FileUploadParams{Filename: "a.txt", MaxSize: 4}},
+		{"unknown size too large", io.MultiReader(bytes.NewReader(make([]byte, MaxSinglePartUploadSize)), strings.NewReader("x")), FileUploadParams{Filename: "a.bin"}},
+		{"invalid part size", bytes.NewReader(make([]byte, MaxSinglePartUploadSize+1)), FileUploadParams{Filename: "a.bin", PartSize: 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := c.FileUploads.Upload(context.Background(), tt.r, tt.params)
+			var ve *ValidationError
+			if !errors.As(err, &ve) || !errors.Is(err, ErrValidation) {
+				t.Fatalf("expected *ValidationError, got %T (%v)", err, err)
+			}
+			if ve.Code != "validation_error" {
+				t.Errorf("Code = %q, want validation_error", ve.Code)
+			}
+		})
+	}
+}
+
+func TestDetectContentTypeSniffsContent(t *testing.T) {
+	got := detectContentType("noext", bufioReader("%PDF-1.7\n"))
+	if got != "application/pdf" {
+		t.Errorf("content type = %q, want application/pdf", got)
+	}
+}
+
+func bufioReader(s string) *bufio.Reader {
+	return bufio.NewReader(strings.NewReader(s))
+}
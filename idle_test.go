@@ -0,0 +1,211 @@
+package notionagents
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"testing"
+	"time"
+)
+
+// stalledStreamResponse sends chunks and then stops sending without closing
+// the connection.
+func stalledStreamResponse(chunks ...StreamChunk) *http.Response {
+	pr, pw := io.Pipe()
+	go func() {
+		for _, c := range chunks {
+			if _, err := pw.Write([]byte(mustJSON(c) + "\n")); err != nil {
+				return
+			}
+		}
+	}()
+	return &http.Response{StatusCode: 200, Body: pr}
+}
+
+func TestStreamIdleTimeout(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return stalledStreamResponse(StreamChunk{Type: "started", ThreadID: "thread-1"}), nil
+	})
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{
+		Message:       "hi",
+		IdleTimeoutMs: 50,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	start := time.Now()
+	var got error
+	for _, err := range reader.All() {
+		got = err
+	}
+	if !errors.Is(got, ErrStreamIdle) {
+		t.Fatalf("err = %v, want ErrStreamIdle", got)
+	}
+	var idleErr *StreamIdleError
+	if !errors.As(got, &idleErr) || idleErr.ThreadID != "thread-1" || idleErr.Timeout != 50*time.Millisecond {
+		t.Errorf("StreamIdleError = %+v", idleErr)
+	}
+	var se *StreamError
+	if !errors.As(got, &se) || se.Code != "stream_idle_timeout" {
+		t.Errorf("StreamError code = %v, want stream_idle_timeout", se)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("stream took %s to time out", elapsed)
+	}
+}
+
+func TestStreamIdleTimeoutIsPerLine(t *testing.T) {
+	pr, pw := io.Pipe()
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: pr}, nil
+	})
+	go func() {
+		for _, chunk := range []StreamChunk{
+			{Type: "started", ThreadID: "thread-1"},
+			{Type: "message", ID: "msg-1", Role: "agent", Content: "a"},
+			{Type: "message", ID: "msg-1", Role: "agent", Content: "ab"},
+			{Type: "message", ID: "msg-1", Role: "agent", Content: "abc"},
+			{Type: "done"},
+		} {
+			time.Sleep(30 * time.Millisecond)
+			pw.Write([]byte(mustJSON(chunk) + "\n"))
+		}
+		pw.Close()
+	}()
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{
+		Message:       "hi",
+		IdleTimeoutMs: 100,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	for _, err := range reader.All() {
+		if err != nil {
+			t.Fatalf("steady stream slower in total than the idle timeout failed: %v", err)
+		}
+	}
+}
+
+func TestStreamIdleTimerAfterReadIsIgnored(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return stalledStreamResponse(
+			StreamChunk{Type: "started", ThreadID: "thread-1"},
+			StreamChunk{Type: "done"},
+		), nil
+	})
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{
+		Message:       "hi",
+		IdleTimeoutMs: 1000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+	// A timer firing between reads, after the line arrived, must not close
+	// the body.
+	reader.onIdle(reader.readGen)
+	if chunk, err := reader.Next(); err != nil || chunk.Type != "done" {
+		t.Fatalf("Next() = %+v, %v; want done chunk", chunk, err)
+	}
+}
+
+func TestStreamIdleTimerFromEarlierReadIsIgnored(t *testing.T) {
+	pr, pw := io.Pipe()
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: 200, Body: pr}, nil
+	})
+	go pw.Write([]byte(mustJSON(StreamChunk{Type: "started", ThreadID: "thread-1"}) + "\n"))
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{
+		Message:       "hi",
+		IdleTimeoutMs: 5000,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+	stale := reader.readGen
+
+	type result struct {
+		chunk StreamChunk
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		chunk, err := reader.Next()
+		done <- result{chunk, err}
+	}()
+	time.Sleep(10 * time.Millisecond)
+
+	// The first read's timer firing late, while the second read is in
+	// progress, must not close the body.
+	reader.onIdle(stale)
+	pw.Write([]byte(mustJSON(StreamChunk{Type: "done"}) + "\n"))
+
+	select {
+	case res := <-done:
+		if res.err != nil || res.chunk.Type != "done" {
+			t.Fatalf("Next() = %+v, %v; want done chunk", res.chunk, res.err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Next did not return")
+	}
+}
+
+func TestStreamIdleTimeoutResumes(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/v1/agents/agent-1/chatStream":
+			return stalledStreamResponse(StreamChunk{Type: "started", ThreadID: "thread-1"}), nil
+		case "/v1/agents/agent-1/threads":
+			return jsonResponse(200, ThreadListResponse{
+				Object:  "list",
+				Results: []ThreadListItem{{ID: "thread-1", Status: ThreadStatusCompleted}},
+			}), nil
+		}
+		return jsonResponse(200, ThreadMessageListResponse{
+			Object: "list",
+			Results: []ThreadMessageItem{
+				{ID: "msg-1", Role: MessageRoleUser, Content: "hi"},
+				{ID: "msg-2", Role: MessageRoleAgent, Content: "Hello!"},
+			},
+		}), nil
+	})
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{
+		Message:       "hi",
+		IdleTimeoutMs: 50,
+		Resumable:     true,
+		ResumePoll:    fastPoll,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	for _, err := range reader.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	info := reader.ThreadInfo()
+	if len(info.Messages) != 1 || info.Messages[0].Content != "Hello!" {
+		t.Errorf("ThreadInfo messages = %+v", info.Messages)
+	}
+}
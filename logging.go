@@ -0,0 +1,81 @@
+package notionagents
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// redacted replaces sensitive header values in log records.
+const redacted = "[REDACTED]"
+
+// attemptKey is the context key carrying the current request attempt.
+type attemptKey struct{}
+
+// withAttempt returns a context recording the zero-based attempt number of
+// the request about to be sent.
+func withAttempt(ctx context.Context, attempt int) context.Context {
+	return context.WithValue(ctx, attemptKey{}, attempt)
+}
+
+// attemptFromContext returns the attempt number recorded by withAttempt.
+func attemptFromContext(ctx context.Context) int {
+	attempt, _ := ctx.Value(attemptKey{}).(int)
+	return attempt
+}
+
+// headerAttrs returns the request headers as a log group, with the
+// Authorization header redacted.
+func headerAttrs(h http.Header) slog.Attr {
+	attrs := make([]any, 0, len(h))
+	for name, values := range h {
+		value := ""
+		if len(values) > 0 {
+			value = values[0]
+		}
+		if http.CanonicalHeaderKey(name) == "Authorization" {
+			value = redacted
+		}
+		attrs = append(attrs, slog.String(name, value))
+	}
+	return slog.Group("headers", attrs...)
+}
+
+// logRequest emits a structured record for a completed request.
+func (c *Client) logRequest(req *http.Request, resp *http.Response, err error, duration time.Duration) {
+	if c.logger == nil {
+		return
+	}
+	ctx := req.Context()
+	attrs := []slog.Attr{
+		slog.String("method", req.Method),
+		slog.String("path", req.URL.Path),
+		slog.Int("attempt", attemptFromContext(ctx)+1),
+		slog.Duration("duration", duration),
+		headerAttrs(req.Header),
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", err.Error()))
+		c.logger.LogAttrs(ctx, slog.LevelError, "notion agents request failed", attrs...)
+		return
+	}
+	attrs = append(attrs,
+		slog.Int("status", resp.StatusCode),
+		slog.String("request_id", resp.Header.Get("x-request-id")),
+	)
+	level := slog.LevelDebug
+	if resp.StatusCode >= 400 {
+		level = slog.LevelWarn
+	}
+	c.logger.LogAttrs(ctx, level, "notion agents request", attrs...)
+}
+
+// log emits a stream lifecycle record if the reader has a logger.
+func (r *StreamReader) log(level slog.Level, msg string, attrs ...slog.Attr) {
+	if r.logger == nil {
+		return
+	}
+	attrs = append(attrs, slog.String("thread_id", r.threadID))
+	r.logger.LogAttrs(context.Background(), level, msg, attrs...)
+}
@@ -0,0 +1,139 @@
+package notionagents
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+// debugLogger returns a logger writing JSON records at debug level to buf.
+func debugLogger(buf *bytes.Buffer) *slog.Logger {
+	return slog.New(slog.NewJSONHandler(buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+}
+
+func decodeLogRecords(t *testing.T, buf *bytes.Buffer) []map[string]interface{} {
+	t.Helper()
+	var records []map[string]interface{}
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var rec map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &rec); err != nil {
+			t.Fatalf("invalid log line %q: %v", line, err)
+		}
+		records = append(records, rec)
+	}
+	return records
+}
+
+func TestLoggerRecordsRequests(t *testing.T) {
+	var buf bytes.Buffer
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		resp := jsonResponse(200, AgentListResponse{Object: "list"})
+		resp.Header.Set("x-request-id", "req-1")
+		return resp, nil
+	}, ClientOptions{Auth: "secret-token", Logger: debugLogger(&buf)})
+
+	if _, err := c.Agents.List(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(buf.String(), "secret-token") {
+		t.Fatal("log output must not contain the API token")
+	}
+
+	records := decodeLogRecords(t, &buf)
+	if len(records) != 1 {
+		t.Fatalf("records = %d, want 1", len(records))
+	}
+	rec := records[0]
+	if rec["method"] != "GET" {
+		t.Errorf("method = %v, want GET", rec["method"])
+	}
+	if rec["path"] != "/v1/agents" {
+		t.Errorf("path = %v, want /v1/agents", rec["path"])
+	}
+	if rec["status"] != float64(200) {
+		t.Errorf("status = %v, want 200", rec["status"])
+	}
+	if rec["attempt"] != float64(1) {
+		t.Errorf("attempt = %v, want 1", rec["attempt"])
+	}
+	if rec["request_id"] != "req-1" {
+		t.Errorf("request_id = %v, want req-1", rec["request_id"])
+	}
+	if _, ok := rec["duration"]; !ok {
+		t.Error("record should include duration")
+	}
+	headers, _ := rec["headers"].(map[string]interface{})
+	if headers["Authorization"] != redacted {
+		t.Errorf("Authorization = %v, want %q", headers["Authorization"], redacted)
+	}
+}
+
+func TestLoggerRecordsRetryAttempts(t *testing.T) {
+	var buf bytes.Buffer
+	calls := 0
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			return jsonResponse(503, map[string]interface{}{"object": "error", "status": 503, "code": "service_unavailable", "message": "busy"}), nil
+		}
+		return jsonResponse(200, AgentListResponse{Object: "list"}), nil
+	}, ClientOptions{
+		Logger:      debugLogger(&buf),
+		RetryPolicy: &RetryPolicy{MaxAttempts: 2, BaseDelayMs: 1, MaxDelayMs: 1},
+	})
+
+	if _, err := c.Agents.List(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	records := decodeLogRecords(t, &buf)
+	if len(records) != 2 {
+		t.Fatalf("records = %d, want 2", len(records))
+	}
+	if records[0]["level"] != "WARN" || records[0]["attempt"] != float64(1) {
+		t.Errorf("first record = %v, want WARN attempt 1", records[0])
+	}
+	if records[1]["level"] != "DEBUG" || records[1]["attempt"] != float64(2) {
+		t.Errorf("second record = %v, want DEBUG attempt 2", records[1])
+	}
+}
+
+func TestLoggerRecordsStreamLifecycle(t *testing.T) {
+	var buf bytes.Buffer
+	r := makeStreamReader(
+		StreamChunk{Type: "started", ThreadID: "t-1", AgentID: "a-1"},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Hi"},
+		StreamChunk{Type: "error", Code: "agent_error", Message: "boom"},
+	)
+	r.logger = debugLogger(&buf)
+
+	for {
+		if _, err := r.Next(); err != nil {
+			break
+		}
+	}
+
+	var msgs []string
+	for _, rec := range decodeLogRecords(t, &buf) {
+		msgs = append(msgs, rec["msg"].(string))
+		if rec["thread_id"] != "t-1" {
+			t.Errorf("thread_id = %v, want t-1", rec["thread_id"])
+		}
+	}
+	want := []string{
+		"notion agents stream started",
+		"notion agents stream message",
+		"notion agents stream error",
+	}
+	if strings.Join(msgs, ",") != strings.Join(want, ",") {
+		t.Errorf("messages = %v, want %v", msgs, want)
+	}
+}
@@ -0,0 +1,38 @@
+package notionagents
+
+import "net/http"
+
+// Handler sends a single HTTP request to the Notion API.
+type Handler func(req *http.Request) (*http.Response, error)
+
+// Middleware wraps a Handler with cross-cutting behavior such as injecting
+// headers, rewriting URLs, recording metrics or short-circuiting requests in
+// tests. Middleware applies to every request the client sends, including
+// streaming requests made by Agent.Stream.
+type Middleware func(next Handler) Handler
+
+// HeaderMiddleware returns a Middleware that sets the given headers on every
+// request.
+func HeaderMiddleware(headers http.Header) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			for name, values := range headers {
+				req.Header.Del(name)
+				for _, v := range values {
+					req.Header.Add(name, v)
+				}
+			}
+			return next(req)
+		}
+	}
+}
+
+// chain applies middleware around base so that the first middleware is the
+// outermost.
+func chain(base Handler, middleware ...Middleware) Handler {
+	next := base
+	for i := len(middleware) - 1; i >= 0; i-- {
+		next = middleware[i](next)
+	}
+	return next
+}
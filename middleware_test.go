@@ -0,0 +1,91 @@
+package notionagents
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestMiddlewareOrder(t *testing.T) {
+	var order []string
+	record := func(name string) Middleware {
+		return func(next Handler) Handler {
+			return func(req *http.Request) (*http.Response, error) {
+				order = append(order, name+":before")
+				resp, err := next(req)
+				order = append(order, name+":after")
+				return resp, err
+			}
+		}
+	}
+
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		order = append(order, "transport")
+		return jsonResponse(200, AgentListResponse{Object: "list"}), nil
+	}, ClientOptions{Middleware: []Middleware{record("outer"), record("inner")}})
+
+	if _, err := c.Agents.List(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+
+	want := "outer:before,inner:before,transport,inner:after,outer:after"
+	if got := strings.Join(order, ","); got != want {
+		t.Errorf("order = %s, want %s", got, want)
+	}
+}
+
+func TestMiddlewareShortCircuit(t *testing.T) {
+	stub := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			return jsonResponse(200, AgentListResponse{Object: "list", Results: []AgentData{{ID: "stub"}}}), nil
+		}
+	}
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		t.Fatal("transport should not be called")
+		return nil, nil
+	}, ClientOptions{Middleware: []Middleware{stub}})
+
+	resp, err := c.Agents.List(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(resp.Results) != 1 || resp.Results[0].ID != "stub" {
+		t.Errorf("results = %+v, want stubbed agent", resp.Results)
+	}
+}
+
+func TestMiddlewareAppliesToStream(t *testing.T) {
+	var gotHeader, gotHost string
+	rehost := func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			req.URL.Host = "acme.example.com"
+			return next(req)
+		}
+	}
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		gotHeader = req.Header.Get("X-Tenant")
+		gotHost = req.URL.Host
+		return ndjsonResponse(StreamChunk{Type: "done"}), nil
+	}, ClientOptions{Middleware: []Middleware{
+		HeaderMiddleware(http.Header{"X-Tenant": []string{"acme"}}),
+		rehost,
+	}})
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+	if _, err := reader.Next(); err != nil && err != io.EOF {
+		t.Fatal(err)
+	}
+
+	if gotHeader != "acme" {
+		t.Errorf("X-Tenant = %q, want %q", gotHeader, "acme")
+	}
+	if gotHost != "acme.example.com" {
+		t.Errorf("host = %q, want %q", gotHost, "acme.example.com")
+	}
+}
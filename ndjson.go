@@ -0,0 +1,64 @@
+package notionagents
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"io"
+)
+
+// DefaultMaxStreamLineSize is the default limit on a single NDJSON line in a
+// streaming response. Verbose chunks carrying large tool outputs can exceed
+// bufio.Scanner's 64KB default by a wide margin.
+const DefaultMaxStreamLineSize = 16 << 20
+
+// errLineTooLong is returned by lineReader when a line exceeds its limit.
+var errLineTooLong = errors.New("line exceeds maximum size")
+
+// lineReader reads newline-delimited lines of any length, growing its buffer
+// as needed up to max bytes. A max of zero or less means unlimited.
+type lineReader struct {
+	r   *bufio.Reader
+	max int
+	buf []byte
+}
+
+func newLineReader(r io.Reader, max int) *lineReader {
+	return &lineReader{r: bufio.NewReader(r), max: max}
+}
+
+// resolveMaxLineSize picks the effective limit from a per-stream and a
+// client-wide setting, where zero defers to the next level and a negative
+// value disables the limit.
+func resolveMaxLineSize(sizes ...int) int {
+	for _, size := range sizes {
+		if size != 0 {
+			return size
+		}
+	}
+	return DefaultMaxStreamLineSize
+}
+
+// ReadLine returns the next line without its trailing "\n" or "\r\n". The
+// returned slice is only valid until the next call. A final line without a
+// newline is returned before io.EOF.
+func (l *lineReader) ReadLine() ([]byte, error) {
+	l.buf = l.buf[:0]
+	for {
+		frag, err := l.r.ReadSlice('\n')
+		l.buf = append(l.buf, frag...)
+		if l.max > 0 && len(bytes.TrimRight(l.buf, "\r\n")) > l.max {
+			return nil, errLineTooLong
+		}
+		switch {
+		case err == nil:
+			return bytes.TrimRight(l.buf, "\r\n"), nil
+		case errors.Is(err, bufio.ErrBufferFull):
+			continue
+		case err == io.EOF && len(l.buf) > 0:
+			return bytes.TrimRight(l.buf, "\r\n"), nil
+		default:
+			return nil, err
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package notionagents
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestLineReader(t *testing.T) {
+	large := strings.Repeat("x", 256<<10)
+	r := newLineReader(strings.NewReader("a\r\n"+large+"\n\nlast"), 0)
+
+	for _, want := range []string{"a", large, "", "last"} {
+		line, err := r.ReadLine()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(line) != want {
+			t.Errorf("line len %d, want len %d", len(line), len(want))
+		}
+	}
+	if _, err := r.ReadLine(); err != io.EOF {
+		t.Errorf("err = %v, want io.EOF", err)
+	}
+}
+
+func TestLineReaderMaxSize(t *testing.T) {
+	r := newLineReader(strings.NewReader("short\n"+strings.Repeat("x", 100)+"\n"), 10)
+
+	if line, err := r.ReadLine(); err != nil || string(line) != "short" {
+		t.Fatalf("ReadLine = %q, %v", line, err)
+	}
+	if _, err := r.ReadLine(); !errors.Is(err, errLineTooLong) {
+		t.Errorf("err = %v, want errLineTooLong", err)
+	}
+}
+
+func TestResolveMaxLineSize(t *testing.T) {
+	tests := []struct {
+		stream, client, want int
+	}{
+		{0, 0, DefaultMaxStreamLineSize},
+		{0, 1024, 1024},
+		{2048, 1024, 2048},
+		{-1, 1024, -1},
+	}
+	for _, tt := range tests {
+		if got := resolveMaxLineSize(tt.stream, tt.client); got != tt.want {
+			t.Errorf("resolveMaxLineSize(%d, %d) = %d, want %d", tt.stream, tt.client, got, tt.want)
+		}
+	}
+}
+
+func TestStreamLargeChunk(t *testing.T) {
+	output := strings.Repeat("o", 1<<20)
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return ndjsonResponse(
+			StreamChunk{Type: "started", ThreadID: "t-1"},
+			StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: output},
+			StreamChunk{Type: "done"},
+		), nil
+	})
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	for _, err := range reader.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if info := reader.ThreadInfo(); len(info.Messages) != 1 || len(info.Messages[0].Content) != len(output) {
+		t.Errorf("large message was not read in full")
+	}
+}
+
+func TestStreamLineTooLong(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return ndjsonResponse(
+			StreamChunk{Type: "started", ThreadID: "t-1"},
+			StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: strings.Repeat("o", 5000)},
+			StreamChunk{Type: "done"},
+		), nil
+	}, ClientOptions{MaxStreamLineSize: 64})
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var got error
+	for _, err := range reader.All() {
+		got = err
+	}
+	var se *StreamError
+	if !errors.As(got, &se) || se.Code != "stream_line_too_long" {
+		t.Errorf("err = %v, want stream_line_too_long", got)
+	}
+
+	// The error ends the stream instead of skipping the oversized line.
+	for i := 0; i < 2; i++ {
+		if _, err := reader.Next(); err != got {
+			t.Errorf("Next() after line too long = %v, want %v", err, got)
+		}
+	}
+}
@@ -0,0 +1,120 @@
+package notionagents
+
+import (
+	"context"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// RateLimit configures a client-side token bucket that every request waits
+// on before being sent, keeping the client under Notion's request quota.
+//
+// In adaptive mode the limiter halves its rate whenever a 429 response is
+// observed, pauses until any Retry-After delay has passed, and gradually
+// recovers to RequestsPerSecond as requests succeed again.
+type RateLimit struct {
+	RequestsPerSecond float64 // Required: sustained request rate
+	Burst             int     // Optional: defaults to 1
+	Adaptive          bool    // Optional: slow down automatically on 429 responses
+}
+
+// rateLimiter is a token bucket safe for concurrent use.
+type rateLimiter struct {
+	mu          sync.Mutex
+	maxRate     float64
+	minRate     float64
+	rate        float64
+	burst       float64
+	tokens      float64
+	last        time.Time
+	pausedUntil time.Time
+	adaptive    bool
+	now         func() time.Time
+}
+
+func newRateLimiter(opts *RateLimit) *rateLimiter {
+	if opts == nil || opts.RequestsPerSecond <= 0 {
+		return nil
+	}
+	burst := opts.Burst
+	if burst <= 0 {
+		burst = 1
+	}
+	return &rateLimiter{
+		maxRate:  opts.RequestsPerSecond,
+		minRate:  opts.RequestsPerSecond / 16,
+		rate:     opts.RequestsPerSecond,
+		burst:    float64(burst),
+		tokens:   float64(burst),
+		adaptive: opts.Adaptive,
+		now:      time.Now,
+	}
+}
+
+// wait blocks until a token is available or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		delay := l.reserve()
+		if delay <= 0 {
+			return nil
+		}
+		if err := sleepContext(ctx, delay); err != nil {
+			return err
+		}
+	}
+}
+
+// reserve takes a token if one is available and otherwise returns how long
+// to wait before trying again.
+func (l *rateLimiter) reserve() time.Duration {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	if now.Before(l.pausedUntil) {
+		return l.pausedUntil.Sub(now)
+	}
+	l.refill(now)
+	if l.tokens >= 1 {
+		l.tokens--
+		return 0
+	}
+	seconds := (1 - l.tokens) / l.rate
+	return time.Duration(math.Ceil(seconds * float64(time.Second)))
+}
+
+// refill adds the tokens accrued since the last call. The caller must hold mu.
+func (l *rateLimiter) refill(now time.Time) {
+	if !l.last.IsZero() {
+		elapsed := now.Sub(l.last).Seconds()
+		l.tokens = math.Min(l.burst, l.tokens+elapsed*l.rate)
+	}
+	l.last = now
+}
+
+// observe adapts the rate to a response when adaptive mode is enabled.
+func (l *rateLimiter) observe(resp *http.Response) {
+	if !l.adaptive || resp == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	l.refill(now)
+	if resp.StatusCode == http.StatusTooManyRequests {
+		l.rate = math.Max(l.rate/2, l.minRate)
+		l.tokens = math.Min(l.tokens, 0)
+		if retryAfter := parseRetryAfter(resp.Header.Get("Retry-After")); retryAfter > 0 {
+			if until := now.Add(retryAfter); until.After(l.pausedUntil) {
+				l.pausedUntil = until
+			}
+		}
+		return
+	}
+	if resp.StatusCode < 400 && l.rate < l.maxRate {
+		l.rate = math.Min(l.rate+l.maxRate/16, l.maxRate)
+	}
+}
@@ -0,0 +1,98 @@
+package notionagents
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestNewRateLimiterDisabled(t *testing.T) {
+	if newRateLimiter(nil) != nil {
+		t.Error("nil options should disable the limiter")
+	}
+	if newRateLimiter(&RateLimit{}) != nil {
+		t.Error("zero RequestsPerSecond should disable the limiter")
+	}
+}
+
+func TestRateLimiterBurstThenWait(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(&RateLimit{RequestsPerSecond: 2, Burst: 2})
+	l.now = func() time.Time { return now }
+
+	if d := l.reserve(); d != 0 {
+		t.Errorf("first reserve = %v, want 0", d)
+	}
+	if d := l.reserve(); d != 0 {
+		t.Errorf("second reserve = %v, want 0", d)
+	}
+	if d := l.reserve(); d != 500*time.Millisecond {
+		t.Errorf("third reserve = %v, want 500ms", d)
+	}
+
+	now = now.Add(500 * time.Millisecond)
+	if d := l.reserve(); d != 0 {
+		t.Errorf("reserve after refill = %v, want 0", d)
+	}
+}
+
+func TestRateLimiterAdaptive(t *testing.T) {
+	now := time.Unix(0, 0)
+	l := newRateLimiter(&RateLimit{RequestsPerSecond: 8, Adaptive: true})
+	l.now = func() time.Time { return now }
+
+	limited := &http.Response{StatusCode: 429, Header: http.Header{"Retry-After": []string{"2"}}}
+	l.observe(limited)
+	if l.rate != 4 {
+		t.Errorf("rate after 429 = %v, want 4", l.rate)
+	}
+	if d := l.reserve(); d != 2*time.Second {
+		t.Errorf("reserve during Retry-After pause = %v, want 2s", d)
+	}
+
+	for range 20 {
+		l.observe(&http.Response{StatusCode: 200})
+	}
+	if l.rate != 8 {
+		t.Errorf("rate after recovery = %v, want 8", l.rate)
+	}
+}
+
+func TestRateLimiterNonAdaptiveIgnores429(t *testing.T) {
+	l := newRateLimiter(&RateLimit{RequestsPerSecond: 8})
+	l.observe(&http.Response{StatusCode: 429, Header: http.Header{}})
+	if l.rate != 8 {
+		t.Errorf("rate = %v, want unchanged 8", l.rate)
+	}
+}
+
+func TestRateLimiterWaitRespectsContext(t *testing.T) {
+	l := newRateLimiter(&RateLimit{RequestsPerSecond: 0.001})
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if err := l.wait(ctx); !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
+
+func TestClientRateLimitAppliesToRequests(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(200, AgentListResponse{Object: "list"}), nil
+	}, ClientOptions{RateLimit: &RateLimit{RequestsPerSecond: 20, Burst: 1}})
+
+	start := time.Now()
+	for range 3 {
+		if _, err := c.Agents.List(context.Background(), nil); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 90*time.Millisecond {
+		t.Errorf("3 requests at 20rps took %v, want at least ~100ms", elapsed)
+	}
+}
@@ -0,0 +1,88 @@
+package notionagents
+
+import (
+	"context"
+	"log/slog"
+	"reflect"
+)
+
+// canResume reports whether a dropped connection can be recovered by
+// polling the thread.
+func (r *StreamReader) canResume() bool {
+	return r.resumable && !r.resumed && !r.done && !r.closed.Load() && r.threadID != "" && r.agent != nil
+}
+
+// resume recovers a dropped stream by polling the thread until it finishes
+// and queueing message chunks for whatever the stream had not yet delivered,
+// followed by a done chunk. cause is the read error that ended the stream,
+// or nil if the body was truncated.
+func (r *StreamReader) resume(cause error) error {
+	r.resumed = true
+	attrs := []slog.Attr{}
+	if cause != nil {
+		attrs = append(attrs, slog.String("cause", cause.Error()))
+	}
+	r.log(slog.LevelWarn, "notion agents stream resuming by polling", attrs...)
+
+	if r.resp != nil && r.resp.Body != nil {
+		r.resp.Body.Close()
+	}
+
+	ctx := r.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	var poll PollThreadOptions
+	if r.resumePoll != nil {
+		poll = *r.resumePoll
+	}
+	poll.Strict = true
+	if _, err := r.agent.PollThread(ctx, r.threadID, &poll); err != nil {
+		return err
+	}
+
+	verbose := r.verbose
+	messages, err := CollectMessages(ctx, r.agent.Thread(r.threadID), &ThreadMessageListParams{Verbose: &verbose})
+	if err != nil {
+		return err
+	}
+
+	for _, msg := range messages[r.resumeStart(messages):] {
+		if msg.Role == MessageRoleUser {
+			continue
+		}
+		if seen, ok := r.messages[msg.ID]; ok && seen.Content == msg.Content && reflect.DeepEqual(seen.ContentParts, msg.ContentParts) {
+			continue
+		}
+		r.pending = append(r.pending, StreamChunk{
+			Type:         "message",
+			ID:           msg.ID,
+			Role:         msg.Role,
+			Content:      msg.Content,
+			Attachments:  msg.Attachments,
+			ContentParts: msg.ContentParts,
+		})
+	}
+	r.pending = append(r.pending, StreamChunk{Type: "done"})
+	return nil
+}
+
+// resumeStart returns the index of the first thread message that belongs to
+// this stream: the first message the stream delivered, or otherwise the
+// message following the last user message.
+func (r *StreamReader) resumeStart(messages []ThreadMessageItem) int {
+	if len(r.msgOrder) > 0 {
+		for i, msg := range messages {
+			if msg.ID == r.msgOrder[0] {
+				return i
+			}
+		}
+	}
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == MessageRoleUser {
+			return i + 1
+		}
+	}
+	return 0
+}
@@ -0,0 +1,201 @@
+package notionagents
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// droppedBody yields data and then fails as if the connection dropped.
+type droppedBody struct {
+	r io.Reader
+}
+
+func (b *droppedBody) Read(p []byte) (int, error) {
+	n, err := b.r.Read(p)
+	if err == io.EOF {
+		return n, errors.New("connection reset by peer")
+	}
+	return n, err
+}
+
+func (b *droppedBody) Close() error { return nil }
+
+func droppedStreamResponse(chunks ...StreamChunk) *http.Response {
+	resp := ndjsonResponse(chunks...)
+	resp.Body = &droppedBody{r: resp.Body}
+	return resp
+}
+
+func resumeClient(t *testing.T) *Client {
+	return mockClient(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/v1/agents/agent-1/chatStream":
+			return droppedStreamResponse(
+				StreamChunk{Type: "started", ThreadID: "thread-1", AgentID: "agent-1"},
+				StreamChunk{Type: "message", ID: "msg-2", Role: "agent", Content: "Part"},
+			), nil
+		case "/v1/agents/agent-1/threads":
+			return jsonResponse(200, ThreadListResponse{
+				Object:  "list",
+				Results: []ThreadListItem{{ID: "thread-1", Status: ThreadStatusCompleted}},
+			}), nil
+		case "/v1/threads/thread-1/messages":
+			return jsonResponse(200, ThreadMessageListResponse{
+				Object: "list",
+				Results: []ThreadMessageItem{
+					{ID: "msg-1", Role: MessageRoleUser, Content: "hi"},
+					{ID: "msg-2", Role: MessageRoleAgent, Content: "Partial answer, now complete."},
+					{ID: "msg-3", Role: MessageRoleAgent, Content: "Anything else?"},
+				},
+			}), nil
+		}
+		t.Fatalf("unexpected request %s", req.URL.Path)
+		return nil, nil
+	})
+}
+
+func TestStreamResumesAfterDroppedConnection(t *testing.T) {
+	c := resumeClient(t)
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{
+		Message:    "hi",
+		Resumable:  true,
+		ResumePoll: fastPoll,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var types []string
+	for {
+		chunk, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, chunk.Type)
+	}
+
+	if got := strings.Join(types, ","); got != "started,message,message,message,done" {
+		t.Errorf("chunk types = %s", got)
+	}
+
+	info := reader.ThreadInfo()
+	if info == nil || len(info.Messages) != 2 {
+		t.Fatalf("ThreadInfo = %+v, want 2 messages", info)
+	}
+	if info.Messages[0].Content != "Partial answer, now complete." {
+		t.Errorf("first message = %q, want completed content", info.Messages[0].Content)
+	}
+	if info.Messages[1].ID != "msg-3" {
+		t.Errorf("second message ID = %q, want msg-3", info.Messages[1].ID)
+	}
+}
+
+func TestStreamWithoutResumableFailsOnDroppedConnection(t *testing.T) {
+	c := resumeClient(t)
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	for {
+		_, err = reader.Next()
+		if err != nil {
+			break
+		}
+	}
+	var se *StreamError
+	if !errors.As(err, &se) || se.Code != "stream_read_error" {
+		t.Errorf("err = %v, want stream_read_error", err)
+	}
+}
+
+func TestStreamCloseDoesNotResume(t *testing.T) {
+	var threadRequests atomic.Int32
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/v1/agents/agent-1/chatStream" {
+			return stalledStreamResponse(StreamChunk{Type: "started", ThreadID: "thread-1", AgentID: "agent-1"}), nil
+		}
+		threadRequests.Add(1)
+		return jsonResponse(200, ThreadListResponse{
+			Object:  "list",
+			Results: []ThreadListItem{{ID: "thread-1", Status: ThreadStatusCompleted}},
+		}), nil
+	})
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{
+		Message:    "hi",
+		Resumable:  true,
+		ResumePoll: fastPoll,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := reader.Next()
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	reader.Close()
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrStreamClosed) {
+			t.Errorf("err = %v, want ErrStreamClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not unblock Next")
+	}
+	if n := threadRequests.Load(); n != 0 {
+		t.Errorf("thread requests after Close = %d, want 0", n)
+	}
+}
+
+func TestStreamResumeReportsFailedThread(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		switch req.URL.Path {
+		case "/v1/agents/agent-1/chatStream":
+			return droppedStreamResponse(StreamChunk{Type: "started", ThreadID: "thread-1", AgentID: "agent-1"}), nil
+		case "/v1/agents/agent-1/threads":
+			return jsonResponse(200, ThreadListResponse{
+				Object:  "list",
+				Results: []ThreadListItem{{ID: "thread-1", Status: ThreadStatusFailed}},
+			}), nil
+		}
+		return jsonResponse(200, ThreadMessageListResponse{Object: "list"}), nil
+	})
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{
+		Message:    "hi",
+		Resumable:  true,
+		ResumePoll: fastPoll,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	for {
+		_, err = reader.Next()
+		if err != nil {
+			break
+		}
+	}
+	if !errors.Is(err, ErrThreadFailed) {
+		t.Errorf("err = %v, want ErrThreadFailed", err)
+	}
+}
@@ -0,0 +1,141 @@
+package notionagents
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+// DefaultRetryableStatusCodes are the HTTP status codes retried when a
+// RetryPolicy does not specify its own.
+var DefaultRetryableStatusCodes = []int{
+	http.StatusTooManyRequests,
+	http.StatusInternalServerError,
+	http.StatusBadGateway,
+	http.StatusServiceUnavailable,
+	http.StatusGatewayTimeout,
+}
+
+// DefaultRetryableErrorCodes are the Notion API error codes retried when a
+// RetryPolicy does not specify its own.
+var DefaultRetryableErrorCodes = []string{
+	"rate_limited",
+	"internal_server_error",
+	"service_unavailable",
+	"database_connection_unavailable",
+	"gateway_timeout",
+}
+
+// RetryPolicy configures automatic retries of failed API requests.
+//
+// A request is retried when its response status is in RetryableStatusCodes
+// or its Notion error code is in RetryableErrorCodes. Delays use exponential
+// backoff with jitter, unless the response carries a Retry-After header, in
+// which case that delay is honored instead.
+type RetryPolicy struct {
+	MaxAttempts          int      // Optional: total attempts including the first, defaults to 3
+	BaseDelayMs          int      // Optional: defaults to 500
+	MaxDelayMs           int      // Optional: defaults to 10000
+	Jitter               *bool    // Optional: defaults to true
+	RetryableStatusCodes []int    // Optional: defaults to DefaultRetryableStatusCodes
+	RetryableErrorCodes  []string // Optional: defaults to DefaultRetryableErrorCodes
+}
+
+func (p *RetryPolicy) maxAttempts() int {
+	if p == nil {
+		return 1
+	}
+	if p.MaxAttempts <= 0 {
+		return 3
+	}
+	return p.MaxAttempts
+}
+
+func (p *RetryPolicy) baseDelayMs() int {
+	if p.BaseDelayMs <= 0 {
+		return 500
+	}
+	return p.BaseDelayMs
+}
+
+func (p *RetryPolicy) maxDelayMs() int {
+	if p.MaxDelayMs <= 0 {
+		return 10000
+	}
+	return p.MaxDelayMs
+}
+
+// retryable reports whether a response with the given status and error code
+// should be retried.
+func (p *RetryPolicy) retryable(statusCode int, code string) bool {
+	if p == nil {
+		return false
+	}
+	statuses := p.RetryableStatusCodes
+	if statuses == nil {
+		statuses = DefaultRetryableStatusCodes
+	}
+	codes := p.RetryableErrorCodes
+	if codes == nil {
+		codes = DefaultRetryableErrorCodes
+	}
+	return slices.Contains(statuses, statusCode) || (code != "" && slices.Contains(codes, code))
+}
+
+// delay returns how long to wait before the next attempt. attempt is the
+// zero-based index of the attempt that just failed.
+func (p *RetryPolicy) delay(attempt int, retryAfter time.Duration) time.Duration {
+	if retryAfter > 0 {
+		return retryAfter
+	}
+	jitter := p.Jitter == nil || *p.Jitter
+	return backoffDelay(attempt, p.baseDelayMs(), p.maxDelayMs(), jitter)
+}
+
+// backoffDelay computes an exponential backoff delay with optional jitter,
+// capped at maxDelayMs.
+func backoffDelay(attempt, baseDelayMs, maxDelayMs int, jitter bool) time.Duration {
+	exponentialDelay := float64(baseDelayMs) * math.Pow(2, float64(attempt))
+	if jitter {
+		exponentialDelay += rand.Float64() * float64(baseDelayMs)
+	}
+	delay := math.Min(exponentialDelay, float64(maxDelayMs))
+	return time.Duration(delay) * time.Millisecond
+}
+
+// sleepContext waits for d or until ctx is done, whichever comes first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// parseRetryAfter parses a Retry-After header value, which may be either a
+// number of seconds or an HTTP date. It returns zero if the value is absent
+// or invalid.
+func parseRetryAfter(value string) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(value); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
@@ -0,0 +1,168 @@
+package notionagents
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestDoJSONRetriesOnServerError(t *testing.T) {
+	calls := 0
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls < 3 {
+			return jsonResponse(502, map[string]interface{}{"object": "error", "status": 502, "code": "bad_gateway", "message": "bad gateway"}), nil
+		}
+		return jsonResponse(200, AgentListResponse{Object: "list", Results: []AgentData{{ID: "a-1"}}}), nil
+	}, ClientOptions{RetryPolicy: &RetryPolicy{MaxAttempts: 3, BaseDelayMs: 1, MaxDelayMs: 1}})
+
+	resp, err := c.Agents.List(context.Background(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 3 {
+		t.Errorf("calls = %d, want 3", calls)
+	}
+	if len(resp.Results) != 1 {
+		t.Errorf("results len = %d, want 1", len(resp.Results))
+	}
+}
+
+func TestDoJSONRetryGivesUpAfterMaxAttempts(t *testing.T) {
+	calls := 0
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(503, map[string]interface{}{"object": "error", "status": 503, "code": "service_unavailable", "message": "unavailable"}), nil
+	}, ClientOptions{RetryPolicy: &RetryPolicy{MaxAttempts: 2, BaseDelayMs: 1, MaxDelayMs: 1}})
+
+	_, err := c.Agents.List(context.Background(), nil)
+	if err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if calls != 2 {
+		t.Errorf("calls = %d, want 2", calls)
+	}
+}
+
+func TestDoJSONNoRetryWithoutPolicy(t *testing.T) {
+	calls := 0
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(500, map[string]interface{}{"object": "error", "status": 500, "code": "internal_server_error", "message": "boom"}), nil
+	})
+
+	if _, err := c.Agents.List(context.Background(), nil); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDoJSONNoRetryOnNonRetryableStatus(t *testing.T) {
+	calls := 0
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		return jsonResponse(400, map[string]interface{}{"object": "error", "status": 400, "code": "validation_error", "message": "bad"}), nil
+	}, ClientOptions{RetryPolicy: &RetryPolicy{MaxAttempts: 3, BaseDelayMs: 1}})
+
+	if _, err := c.Agents.List(context.Background(), nil); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+}
+
+func TestDoJSONRetryHonorsRetryAfter(t *testing.T) {
+	calls := 0
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		calls++
+		if calls == 1 {
+			resp := jsonResponse(429, map[string]interface{}{"object": "error", "status": 429, "code": "rate_limited", "message": "slow down"})
+			resp.Header.Set("Retry-After", "1")
+			return resp, nil
+		}
+		return jsonResponse(200, AgentListResponse{Object: "list"}), nil
+	}, ClientOptions{RetryPolicy: &RetryPolicy{MaxAttempts: 2, BaseDelayMs: 1, MaxDelayMs: 1}})
+
+	start := time.Now()
+	if _, err := c.Agents.List(context.Background(), nil); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < time.Second {
+		t.Errorf("elapsed = %v, want at least 1s from Retry-After", elapsed)
+	}
+}
+
+func TestDoJSONRetryRespectsContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		cancel()
+		return jsonResponse(500, map[string]interface{}{"object": "error", "status": 500, "code": "internal_server_error", "message": "boom"}), nil
+	}, ClientOptions{RetryPolicy: &RetryPolicy{MaxAttempts: 5, BaseDelayMs: 10000, MaxDelayMs: 10000}})
+
+	_, err := c.Agents.List(ctx, nil)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestRetryPolicyRetryable(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *RetryPolicy
+		status int
+		code   string
+		want   bool
+	}{
+		{"nil policy", nil, 500, "", false},
+		{"default status", &RetryPolicy{}, 429, "", true},
+		{"default code", &RetryPolicy{}, 400, "rate_limited", true},
+		{"non retryable", &RetryPolicy{}, 404, "object_not_found", false},
+		{"custom status", &RetryPolicy{RetryableStatusCodes: []int{409}}, 409, "", true},
+		{"custom status excludes default", &RetryPolicy{RetryableStatusCodes: []int{409}}, 500, "", false},
+		{"custom code", &RetryPolicy{RetryableErrorCodes: []string{"conflict_error"}}, 409, "conflict_error", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.policy.retryable(tt.status, tt.code); got != tt.want {
+				t.Errorf("retryable(%d, %q) = %v, want %v", tt.status, tt.code, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBackoffDelay(t *testing.T) {
+	if got := backoffDelay(0, 100, 1000, false); got != 100*time.Millisecond {
+		t.Errorf("attempt 0 = %v, want 100ms", got)
+	}
+	if got := backoffDelay(2, 100, 1000, false); got != 400*time.Millisecond {
+		t.Errorf("attempt 2 = %v, want 400ms", got)
+	}
+	if got := backoffDelay(10, 100, 1000, true); got != time.Second {
+		t.Errorf("attempt 10 = %v, want capped at 1s", got)
+	}
+	if got := backoffDelay(0, 100, 1000, true); got < 100*time.Millisecond || got > 200*time.Millisecond {
+		t.Errorf("attempt 0 with jitter = %v, want between 100ms and 200ms", got)
+	}
+}
+
+func TestParseRetryAfter(t *testing.T) {
+	if got := parseRetryAfter("3"); got != 3*time.Second {
+		t.Errorf("parseRetryAfter(3) = %v, want 3s", got)
+	}
+	if got := parseRetryAfter(""); got != 0 {
+		t.Errorf("parseRetryAfter(\"\") = %v, want 0", got)
+	}
+	if got := parseRetryAfter("garbage"); got != 0 {
+		t.Errorf("parseRetryAfter(garbage) = %v, want 0", got)
+	}
+	future := time.Now().Add(time.Minute).UTC().Format(http.TimeFormat)
+	if got := parseRetryAfter(future); got <= 0 || got > time.Minute {
+		t.Errorf("parseRetryAfter(date) = %v, want within (0, 1m]", got)
+	}
+}
@@ -1,93 +1,324 @@
 package notionagents
 
 import (
-	"bufio"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"iter"
+	"log/slog"
 	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
 // StreamReader reads streaming chat responses using an iterator pattern.
 type StreamReader struct {
 	resp      *http.Response
-	scanner   *bufio.Scanner
+	lines     *lineReader
 	threadID  string
 	agentID   string
 	messages  map[string]*StreamMessage
 	msgOrder  []string
 	done      bool
+	closed    atomic.Bool // Set by Close, which may run concurrently with Next
+	err       error       // Permanent error returned by every later Next
 	onMessage func(StreamMessage)
+	logger    *slog.Logger
+
+	// Resumable streams fall back to polling the thread when the
+	// connection drops; recovered chunks are queued in pending.
+	ctx        context.Context
+	agent      *Agent
+	resumable  bool
+	resumed    bool
+	resumePoll *PollThreadOptions
+	verbose    bool
+	pending    []StreamChunk
+
+	// partState tracks content part events already emitted by Events.
+	partState map[string]int
+
+	// An idle timer closes the body when a read waits longer than
+	// idleTimeout; idled records that it fired. Each read gets a new
+	// generation, so a timer left over from an earlier read is ignored.
+	// idleMu guards the fields below, which Close also uses.
+	idleTimeout time.Duration
+	idleMu      sync.Mutex
+	idleTimer   *time.Timer
+	readGen     uint64
+	reading     bool
+	idled       bool
 }
 
 // Next returns the next chunk from the stream.
 // Returns io.EOF when the stream is complete.
 func (r *StreamReader) Next() (StreamChunk, error) {
+	if r.closed.Load() {
+		return StreamChunk{}, errReaderClosed()
+	}
+	if r.err != nil {
+		return StreamChunk{}, r.err
+	}
 	if r.done {
 		return StreamChunk{}, io.EOF
 	}
 
-	for r.scanner.Scan() {
-		line := r.scanner.Text()
-		if line == "" {
+	chunk, err := r.read()
+	if err != nil {
+		return StreamChunk{}, err
+	}
+
+	switch chunk.Type {
+	case "started":
+		r.threadID = chunk.ThreadID
+		r.agentID = chunk.AgentID
+		r.log(slog.LevelDebug, "notion agents stream started", slog.String("agent_id", r.agentID))
+
+	case "message":
+		prev, exists := r.messages[chunk.ID]
+		if !exists {
+			r.msgOrder = append(r.msgOrder, chunk.ID)
+		}
+		chunk.Delta = messageDelta(prev, chunk)
+		r.messages[chunk.ID] = &StreamMessage{
+			ID:           chunk.ID,
+			Role:         chunk.Role,
+			Content:      chunk.Content,
+			Attachments:  chunk.Attachments,
+			ContentParts: chunk.ContentParts,
+		}
+		r.log(slog.LevelDebug, "notion agents stream message",
+			slog.String("message_id", chunk.ID),
+			slog.String("role", chunk.Role),
+			slog.Int("content_length", len(chunk.Content)),
+		)
+		if r.onMessage != nil {
+			r.onMessage(*r.messages[chunk.ID])
+		}
+
+	case "done":
+		r.done = true
+		r.log(slog.LevelDebug, "notion agents stream done", slog.Int("messages", len(r.msgOrder)))
+
+	case "error":
+		return StreamChunk{}, r.fail(&StreamError{
+			Msg:  chunk.Message,
+			Code: chunk.Code,
+		})
+	}
+
+	return chunk, nil
+}
+
+// read returns the next raw chunk, taken from chunks recovered by resuming
+// the stream if any are queued and otherwise from the response body.
+func (r *StreamReader) read() (StreamChunk, error) {
+	if len(r.pending) > 0 {
+		chunk := r.pending[0]
+		r.pending = r.pending[1:]
+		return chunk, nil
+	}
+
+	for {
+		line, err := r.readLine()
+		if errors.Is(err, errLineTooLong) {
+			// The rest of the line is still unread, so the stream cannot
+			// continue past it.
+			r.err = r.fail(&StreamError{
+				Msg:  fmt.Sprintf("stream line exceeds maximum size of %d bytes", r.lines.max),
+				Code: "stream_line_too_long",
+			})
+			return StreamChunk{}, r.err
+		}
+		if err != nil {
+			if r.isIdled() {
+				return r.endOfBody(&StreamIdleError{
+					StreamError: StreamError{
+						Msg:  fmt.Sprintf("no data received for %s", r.idleTimeout),
+						Code: "stream_idle_timeout",
+					},
+					ThreadID: r.threadID,
+					Timeout:  r.idleTimeout,
+				})
+			}
+			if err == io.EOF {
+				err = nil
+			}
+			return r.endOfBody(err)
+		}
+		if len(line) == 0 {
 			continue
 		}
 
 		var chunk StreamChunk
-		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
-			return StreamChunk{}, &StreamError{
+		if err := json.Unmarshal(line, &chunk); err != nil {
+			return StreamChunk{}, r.fail(&StreamError{
 				Msg:  fmt.Sprintf("failed to parse stream chunk: %s", err),
 				Code: "invalid_stream_response",
-			}
+			})
 		}
+		return chunk, nil
+	}
+}
 
-		switch chunk.Type {
-		case "started":
-			r.threadID = chunk.ThreadID
-			r.agentID = chunk.AgentID
+// readLine reads the next line, closing the body if it takes longer than
+// the idle timeout.
+func (r *StreamReader) readLine() ([]byte, error) {
+	if r.idleTimeout <= 0 {
+		return r.lines.ReadLine()
+	}
+	r.startRead()
+	line, err := r.lines.ReadLine()
+	r.endRead()
+	return line, err
+}
 
-		case "message":
-			if _, exists := r.messages[chunk.ID]; !exists {
-				r.msgOrder = append(r.msgOrder, chunk.ID)
-			}
-			r.messages[chunk.ID] = &StreamMessage{
-				ID:           chunk.ID,
-				Role:         chunk.Role,
-				Content:      chunk.Content,
-				Attachments:  chunk.Attachments,
-				ContentParts: chunk.ContentParts,
-			}
-			if r.onMessage != nil {
-				r.onMessage(*r.messages[chunk.ID])
-			}
+// startRead starts a new read generation and arms the idle timer for it.
+func (r *StreamReader) startRead() {
+	r.idleMu.Lock()
+	defer r.idleMu.Unlock()
+	r.readGen++
+	r.reading = true
+	gen := r.readGen
+	r.idleTimer = time.AfterFunc(r.idleTimeout, func() { r.onIdle(gen) })
+}
+
+func (r *StreamReader) endRead() {
+	r.idleMu.Lock()
+	defer r.idleMu.Unlock()
+	r.reading = false
+	r.idleTimer.Stop()
+}
+
+func (r *StreamReader) isIdled() bool {
+	r.idleMu.Lock()
+	defer r.idleMu.Unlock()
+	return r.idled
+}
+
+// onIdle runs on the idle timer's goroutine and unblocks the read of
+// generation gen. A timer that fires after that read has returned, even
+// while a later read is in progress, does nothing.
+func (r *StreamReader) onIdle(gen uint64) {
+	r.idleMu.Lock()
+	defer r.idleMu.Unlock()
+	if !r.reading || gen != r.readGen {
+		return
+	}
+	r.idled = true
+	if r.resp != nil && r.resp.Body != nil {
+		r.resp.Body.Close()
+	}
+}
+
+// endOfBody handles the connection ending before a done chunk: either the
+// read failed with readErr or the body was truncated.
+func (r *StreamReader) endOfBody(readErr error) (StreamChunk, error) {
+	// Close from another goroutine fails the pending read; that is not a
+	// dropped connection.
+	if r.closed.Load() {
+		return StreamChunk{}, errReaderClosed()
+	}
+	if r.canResume() {
+		if err := r.resume(readErr); err != nil {
+			return StreamChunk{}, err
+		}
+		return r.read()
+	}
 
-		case "done":
-			r.done = true
-			return chunk, nil
+	var idleErr *StreamIdleError
+	if errors.As(readErr, &idleErr) {
+		r.fail(&idleErr.StreamError)
+		return StreamChunk{}, idleErr
+	}
+	if readErr != nil {
+		return StreamChunk{}, r.fail(&StreamError{
+			Msg:  fmt.Sprintf("read error: %s", readErr),
+			Code: "stream_read_error",
+		})
+	}
 
-		case "error":
-			return StreamChunk{}, &StreamError{
-				Msg:  chunk.Message,
-				Code: chunk.Code,
+	return StreamChunk{}, io.EOF
+}
+
+// All returns an iterator over the remaining chunks in the stream. If the
+// loop exits early, the response body is closed. ThreadInfo remains
+// available once iteration ends.
+func (r *StreamReader) All() iter.Seq2[StreamChunk, error] {
+	return func(yield func(StreamChunk, error) bool) {
+		for {
+			chunk, err := r.Next()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				yield(StreamChunk{}, err)
+				return
+			}
+			if !yield(chunk, nil) {
+				r.Close()
+				return
 			}
 		}
+	}
+}
 
-		return chunk, nil
+// messageDelta computes the change chunk makes to prev, the message as of
+// the previous chunk with the same ID (nil for a new message).
+func messageDelta(prev *StreamMessage, chunk StreamChunk) *StreamDelta {
+	if prev == nil {
+		prev = &StreamMessage{}
+	}
+	if !strings.HasPrefix(chunk.Content, prev.Content) || len(chunk.ContentParts) < len(prev.ContentParts) {
+		delta := &StreamDelta{Text: chunk.Content, Replace: true, Parts: chunk.ContentParts}
+		for i := range chunk.ContentParts {
+			delta.PartIndexes = append(delta.PartIndexes, i)
+		}
+		return delta
 	}
 
-	if err := r.scanner.Err(); err != nil {
-		return StreamChunk{}, &StreamError{
-			Msg:  fmt.Sprintf("scanner error: %s", err),
-			Code: "stream_read_error",
+	delta := &StreamDelta{Text: chunk.Content[len(prev.Content):]}
+	for i, part := range chunk.ContentParts {
+		if i >= len(prev.ContentParts) || !reflect.DeepEqual(prev.ContentParts[i], part) {
+			delta.Parts = append(delta.Parts, part)
+			delta.PartIndexes = append(delta.PartIndexes, i)
 		}
 	}
+	return delta
+}
 
-	return StreamChunk{}, io.EOF
+// fail logs a stream error and returns it.
+func (r *StreamReader) fail(err *StreamError) error {
+	r.log(slog.LevelError, "notion agents stream error",
+		slog.String("code", err.Code),
+		slog.String("error", err.Msg),
+	)
+	return err
 }
 
-// Close closes the underlying response body.
+// errReaderClosed is returned by Next once the reader is closed.
+func errReaderClosed() error {
+	return &StreamError{
+		Msg:  "stream reader is closed",
+		Code: "stream_closed",
+	}
+}
+
+// Close closes the underlying response body. Calling Next after Close
+// returns an error matching ErrStreamClosed. Close may be called from
+// another goroutine to unblock a pending Next.
 func (r *StreamReader) Close() error {
+	r.closed.Store(true)
+	r.idleMu.Lock()
+	if r.idleTimer != nil {
+		r.idleTimer.Stop()
+	}
+	r.idleMu.Unlock()
 	if r.resp != nil && r.resp.Body != nil {
 		return r.resp.Body.Close()
 	}
@@ -117,10 +348,7 @@ func (r *StreamReader) ThreadInfo() *ThreadInfo {
 // Stream opens a streaming chat connection and returns a StreamReader.
 func (a *Agent) Stream(ctx context.Context, params ChatStreamParams) (*StreamReader, error) {
 	if params.Message == "" && len(params.Attachments) == 0 {
-		return nil, &NotionAgentsError{
-			Msg:  "Either message or attachments is required.",
-			Code: "validation_error",
-		}
+		return nil, newValidationError("Either message or attachments is required.")
 	}
 
 	body := chatRequestBody{
@@ -145,6 +373,7 @@ func (a *Agent) Stream(ctx context.Context, params ChatStreamParams) (*StreamRea
 		path += "?verbose=true"
 	}
 
+	ctx = withOperation(ctx, Operation{Name: OpAgentChatStream, AgentID: a.ID, ThreadID: params.ThreadID})
 	resp, err := a.client.doRequest(ctx, http.MethodPost, path, body)
 	if err != nil {
 		return nil, err
@@ -156,6 +385,7 @@ func (a *Agent) Stream(ctx context.Context, params ChatStreamParams) (*StreamRea
 		return nil, &StreamError{
 			Msg:  fmt.Sprintf("HTTP %d: %s", resp.StatusCode, string(respBody)),
 			Code: "http_error",
+			Err:  newAPIError(resp, respBody),
 		}
 	}
 
@@ -167,51 +397,26 @@ func (a *Agent) Stream(ctx context.Context, params ChatStreamParams) (*StreamRea
 	}
 
 	return &StreamReader{
-		resp:      resp,
-		scanner:   bufio.NewScanner(resp.Body),
-		messages:  make(map[string]*StreamMessage),
-		onMessage: params.OnMessage,
+		resp:        resp,
+		lines:       newLineReader(resp.Body, resolveMaxLineSize(params.MaxLineSize, a.client.maxLineSize)),
+		messages:    make(map[string]*StreamMessage),
+		onMessage:   params.OnMessage,
+		logger:      a.client.logger,
+		ctx:         ctx,
+		agent:       a,
+		resumable:   params.Resumable,
+		resumePoll:  params.ResumePoll,
+		verbose:     verbose,
+		idleTimeout: time.Duration(params.IdleTimeoutMs) * time.Millisecond,
 	}, nil
 }
 
-// ChatStream opens a streaming chat and returns channels for chunks, thread info, and errors.
-func (a *Agent) ChatStream(ctx context.Context, params ChatStreamParams) (<-chan StreamChunk, <-chan *ThreadInfo, <-chan error) {
-	chunks := make(chan StreamChunk)
-	info := make(chan *ThreadInfo, 1)
-	errc := make(chan error, 1)
-
-	go func() {
-		defer close(chunks)
-		defer close(info)
-		defer close(errc)
-
-		reader, err := a.Stream(ctx, params)
-		if err != nil {
-			errc <- err
-			return
-		}
-		defer reader.Close()
-
-		for {
-			chunk, err := reader.Next()
-			if err == io.EOF {
-				if ti := reader.ThreadInfo(); ti != nil {
-					info <- ti
-				}
-				return
-			}
-			if err != nil {
-				errc <- err
-				return
-			}
-			select {
-			case chunks <- chunk:
-			case <-ctx.Done():
-				errc <- ctx.Err()
-				return
-			}
-		}
-	}()
-
-	return chunks, info, errc
+// StreamWithFiles uploads each file at paths and opens a streaming chat with
+// them attached, named after each file's base name.
+func (a *Agent) StreamWithFiles(ctx context.Context, message string, paths ...string) (*StreamReader, error) {
+	attachments, err := a.client.FileUploads.uploadAttachments(ctx, paths)
+	if err != nil {
+		return nil, err
+	}
+	return a.Stream(ctx, ChatStreamParams{Message: message, Attachments: attachments})
 }
@@ -1,11 +1,15 @@
 package notionagents
 
 import (
-	"bufio"
+	"context"
 	"encoding/json"
+	"errors"
 	"io"
+	"net/http"
+	"slices"
 	"strings"
 	"testing"
+	"time"
 )
 
 func makeStreamReader(chunks ...StreamChunk) *StreamReader {
@@ -17,7 +21,7 @@ func makeStreamReader(chunks ...StreamChunk) *StreamReader {
 	body := strings.Join(lines, "\n") + "\n"
 
 	return &StreamReader{
-		scanner:  bufio.NewScanner(strings.NewReader(body)),
+		lines:    newLineReader(strings.NewReader(body), 0),
 		messages: make(map[string]*StreamMessage),
 	}
 }
@@ -176,7 +180,7 @@ func TestStreamReaderErrorChunk(t *testing.T) {
 
 func TestStreamReaderInvalidJSON(t *testing.T) {
 	r := &StreamReader{
-		scanner:  bufio.NewScanner(strings.NewReader("not valid json\n")),
+		lines:    newLineReader(strings.NewReader("not valid json\n"), 0),
 		messages: make(map[string]*StreamMessage),
 	}
 
@@ -196,7 +200,7 @@ func TestStreamReaderInvalidJSON(t *testing.T) {
 
 func TestStreamReaderEmptyStream(t *testing.T) {
 	r := &StreamReader{
-		scanner:  bufio.NewScanner(strings.NewReader("")),
+		lines:    newLineReader(strings.NewReader(""), 0),
 		messages: make(map[string]*StreamMessage),
 	}
 
@@ -210,10 +214,10 @@ func TestStreamReaderSkipsBlankLines(t *testing.T) {
 	r := makeStreamReader(
 		StreamChunk{Type: "started", ThreadID: "t-1", AgentID: "a-1"},
 	)
-	// Inject blank lines by recreating scanner with blanks
-	r.scanner = bufio.NewScanner(strings.NewReader(
-		"\n\n" + mustJSON(StreamChunk{Type: "started", ThreadID: "t-1"}) + "\n\n",
-	))
+	// Inject blank lines by recreating the line reader with blanks
+	r.lines = newLineReader(strings.NewReader(
+		"\n\n"+mustJSON(StreamChunk{Type: "started", ThreadID: "t-1"})+"\n\n",
+	), 0)
 
 	chunk, err := r.Next()
 	if err != nil {
@@ -255,7 +259,7 @@ func TestStreamReaderOnMessageCallback(t *testing.T) {
 
 func TestStreamReaderThreadInfoBeforeStarted(t *testing.T) {
 	r := &StreamReader{
-		scanner:  bufio.NewScanner(strings.NewReader("")),
+		lines:    newLineReader(strings.NewReader(""), 0),
 		messages: make(map[string]*StreamMessage),
 	}
 
@@ -308,3 +312,159 @@ func mustJSON(v interface{}) string {
 	data, _ := json.Marshal(v)
 	return string(data)
 }
+
+func TestStreamReaderNextAfterClose(t *testing.T) {
+	r := makeStreamReader(
+		StreamChunk{Type: "started", ThreadID: "t-1", AgentID: "a-1"},
+	)
+	if err := r.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := r.Next()
+	if !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("err = %v, want ErrStreamClosed", err)
+	}
+}
+
+func TestStreamReaderCloseUnblocksNext(t *testing.T) {
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return stalledStreamResponse(StreamChunk{Type: "started", ThreadID: "t-1"}), nil
+	})
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := reader.Next(); err != nil {
+		t.Fatal(err)
+	}
+
+	errc := make(chan error, 1)
+	go func() {
+		_, err := reader.Next()
+		errc <- err
+	}()
+	time.Sleep(10 * time.Millisecond)
+	if err := reader.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	select {
+	case err := <-errc:
+		if !errors.Is(err, ErrStreamClosed) {
+			t.Errorf("err = %v, want ErrStreamClosed", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Close did not unblock Next")
+	}
+}
+
+func TestStreamReaderDelta(t *testing.T) {
+	callID := "call-1"
+	r := makeStreamReader(
+		StreamChunk{Type: "started", ThreadID: "t-1"},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Hel"},
+		StreamChunk{Type: "message", ID: "msg-2", Role: "agent", Content: "Other"},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Hello", ContentParts: []AgentContentPart{
+			{Type: "text", Text: "Hello"},
+			{Type: "tool_call", ToolCallID: &callID, ToolName: "search"},
+		}},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Hello", ContentParts: []AgentContentPart{
+			{Type: "text", Text: "Hello"},
+			{Type: "tool_call", ToolCallID: &callID, ToolName: "search", Results: []ToolResult{{ID: "r-1"}}},
+		}},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Goodbye"},
+		StreamChunk{Type: "done"},
+	)
+
+	want := []struct {
+		text    string
+		replace bool
+		indexes []int
+	}{
+		{"Hel", false, nil},
+		{"Other", false, nil},
+		{"lo", false, []int{0, 1}},
+		{"", false, []int{1}},
+		{"Goodbye", true, nil},
+	}
+
+	if _, err := r.Next(); err != nil {
+		t.Fatal(err)
+	}
+	for i, w := range want {
+		chunk, err := r.Next()
+		if err != nil {
+			t.Fatal(err)
+		}
+		d := chunk.Delta
+		if d == nil {
+			t.Fatalf("chunk %d: Delta is nil", i)
+		}
+		if d.Text != w.text || d.Replace != w.replace || !slices.Equal(d.PartIndexes, w.indexes) || len(d.Parts) != len(w.indexes) {
+			t.Errorf("chunk %d: Delta = {%q, %v, parts at %v}, want {%q, %v, parts at %v}",
+				i, d.Text, d.Replace, d.PartIndexes, w.text, w.replace, w.indexes)
+		}
+	}
+
+	chunk, err := r.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if chunk.Delta != nil {
+		t.Errorf("done chunk Delta = %+v, want nil", chunk.Delta)
+	}
+}
+
+func TestStreamReaderAll(t *testing.T) {
+	r := makeStreamReader(
+		StreamChunk{Type: "started", ThreadID: "t-1", AgentID: "a-1"},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Hi"},
+		StreamChunk{Type: "done"},
+	)
+
+	var types []string
+	for chunk, err := range r.All() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		types = append(types, chunk.Type)
+	}
+	if strings.Join(types, ",") != "started,message,done" {
+		t.Errorf("chunk types = %v", types)
+	}
+	if info := r.ThreadInfo(); info == nil || len(info.Messages) != 1 {
+		t.Errorf("ThreadInfo = %+v, want 1 message", info)
+	}
+}
+
+func TestStreamReaderAllEarlyExitCloses(t *testing.T) {
+	r := makeStreamReader(
+		StreamChunk{Type: "started", ThreadID: "t-1"},
+		StreamChunk{Type: "message", ID: "msg-1", Role: "agent", Content: "Hi"},
+		StreamChunk{Type: "done"},
+	)
+
+	for range r.All() {
+		break
+	}
+	if _, err := r.Next(); !errors.Is(err, ErrStreamClosed) {
+		t.Errorf("Next after early exit = %v, want ErrStreamClosed", err)
+	}
+	if info := r.ThreadInfo(); info == nil || info.ThreadID != "t-1" {
+		t.Errorf("ThreadInfo = %+v, want thread t-1", info)
+	}
+}
+
+func TestStreamReaderAllError(t *testing.T) {
+	r := makeStreamReader(StreamChunk{Type: "error", Code: "internal_error", Message: "boom"})
+
+	var got error
+	for _, err := range r.All() {
+		got = err
+	}
+	var se *StreamError
+	if !errors.As(got, &se) || se.Code != "internal_error" {
+		t.Errorf("err = %v, want internal_error", got)
+	}
+}
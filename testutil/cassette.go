@@ -0,0 +1,357 @@
+package testutil
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// scrubbedHeaders are replaced in recorded requests so cassettes can be
+// committed without leaking credentials.
+var scrubbedHeaders = []string{"Authorization"}
+
+const scrubbedValue = "[REDACTED]"
+
+// Cassette is a recorded sequence of HTTP interactions.
+type Cassette struct {
+	Interactions []*Interaction `json:"interactions"`
+}
+
+// Interaction is a single recorded request and its response.
+type Interaction struct {
+	Request  RecordedRequest  `json:"request"`
+	Response RecordedResponse `json:"response"`
+}
+
+// RecordedRequest is the recorded form of an outgoing request. Path
+// includes the query string.
+type RecordedRequest struct {
+	Method  string      `json:"method"`
+	Path    string      `json:"path"`
+	Headers http.Header `json:"headers,omitempty"`
+	Body    string      `json:"body,omitempty"`
+}
+
+// RecordedResponse is the recorded form of a response. Streaming (NDJSON)
+// responses are stored as Lines with the delay before each line; other
+// responses are stored in Body.
+type RecordedResponse struct {
+	StatusCode int          `json:"status_code"`
+	Headers    http.Header  `json:"headers,omitempty"`
+	Body       string       `json:"body,omitempty"`
+	Lines      []StreamLine `json:"lines,omitempty"`
+}
+
+// StreamLine is one line of a recorded NDJSON body.
+type StreamLine struct {
+	Line    string `json:"line"`
+	DelayMs int64  `json:"delay_ms"` // Time since the previous line, or since the response headers
+}
+
+// LoadCassette reads a cassette from a JSON file.
+func LoadCassette(path string) (*Cassette, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading cassette: %w", err)
+	}
+	var c Cassette
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing cassette %s: %w", path, err)
+	}
+	return &c, nil
+}
+
+// Save writes the cassette to path as indented JSON.
+func (c *Cassette) Save(path string) error {
+	data, err := json.MarshalIndent(c, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encoding cassette: %w", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+		return fmt.Errorf("writing cassette: %w", err)
+	}
+	return nil
+}
+
+func isNDJSON(header http.Header) bool {
+	return strings.HasPrefix(header.Get("Content-Type"), "application/x-ndjson")
+}
+
+// Recorder is an http.RoundTripper that forwards requests to a real
+// transport and records every interaction. Streaming bodies are passed
+// through as they arrive, recording the timing of each line.
+type Recorder struct {
+	transport http.RoundTripper
+
+	mu       sync.Mutex
+	cassette Cassette
+}
+
+// NewRecorder returns a Recorder that sends requests with transport, or
+// http.DefaultTransport if nil.
+func NewRecorder(transport http.RoundTripper) *Recorder {
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	return &Recorder{transport: transport}
+}
+
+// Client returns an *http.Client that records through r.
+func (r *Recorder) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Recorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	headers := req.Header.Clone()
+	for _, name := range scrubbedHeaders {
+		if headers.Get(name) != "" {
+			headers.Set(name, scrubbedValue)
+		}
+	}
+
+	resp, err := r.transport.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	interaction := &Interaction{
+		Request: RecordedRequest{
+			Method:  req.Method,
+			Path:    req.URL.RequestURI(),
+			Headers: headers,
+			Body:    string(body),
+		},
+		Response: RecordedResponse{
+			StatusCode: resp.StatusCode,
+			Headers:    resp.Header.Clone(),
+		},
+	}
+	r.mu.Lock()
+	r.cassette.Interactions = append(r.cassette.Interactions, interaction)
+	r.mu.Unlock()
+
+	if isNDJSON(resp.Header) {
+		resp.Body = &lineRecordingBody{
+			rec:  r,
+			resp: &interaction.Response,
+			src:  resp.Body,
+			r:    bufio.NewReader(resp.Body),
+			last: time.Now(),
+		}
+		return resp, nil
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading response body: %w", err)
+	}
+	r.mu.Lock()
+	interaction.Response.Body = string(data)
+	r.mu.Unlock()
+	resp.Body = io.NopCloser(bytes.NewReader(data))
+	return resp, nil
+}
+
+// Cassette returns a copy of the interactions recorded so far.
+func (r *Recorder) Cassette() *Cassette {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	data, _ := json.Marshal(r.cassette)
+	var c Cassette
+	json.Unmarshal(data, &c)
+	return &c
+}
+
+// Save writes the interactions recorded so far to path.
+func (r *Recorder) Save(path string) error {
+	return r.Cassette().Save(path)
+}
+
+// lineRecordingBody passes a streaming body through to the caller while
+// recording each line and the delay before it.
+type lineRecordingBody struct {
+	rec  *Recorder
+	resp *RecordedResponse
+	src  io.Closer
+	r    *bufio.Reader
+	last time.Time
+	buf  []byte
+}
+
+func (b *lineRecordingBody) Read(p []byte) (int, error) {
+	if len(b.buf) == 0 {
+		line, err := b.r.ReadBytes('\n')
+		if len(line) > 0 {
+			now := time.Now()
+			b.rec.mu.Lock()
+			b.resp.Lines = append(b.resp.Lines, StreamLine{
+				Line:    strings.TrimRight(string(line), "\r\n"),
+				DelayMs: now.Sub(b.last).Milliseconds(),
+			})
+			b.rec.mu.Unlock()
+			b.last = now
+			b.buf = line
+		}
+		if len(b.buf) == 0 {
+			return 0, err
+		}
+	}
+	n := copy(p, b.buf)
+	b.buf = b.buf[n:]
+	return n, nil
+}
+
+func (b *lineRecordingBody) Close() error {
+	return b.src.Close()
+}
+
+// Replayer is an http.RoundTripper that answers requests from a cassette.
+// Each interaction is used once, in recorded order, for the first request
+// with the same method, path and body. JSON bodies are compared by value.
+type Replayer struct {
+	// Paced replays streaming lines with their recorded delays instead of
+	// all at once.
+	Paced bool
+
+	mu       sync.Mutex
+	cassette *Cassette
+	used     []bool
+}
+
+// NewReplayer returns a Replayer for c.
+func NewReplayer(c *Cassette) *Replayer {
+	return &Replayer{cassette: c, used: make([]bool, len(c.Interactions))}
+}
+
+// LoadReplayer reads the cassette at path and returns a Replayer for it.
+func LoadReplayer(path string) (*Replayer, error) {
+	c, err := LoadCassette(path)
+	if err != nil {
+		return nil, err
+	}
+	return NewReplayer(c), nil
+}
+
+// Client returns an *http.Client that replays from r.
+func (r *Replayer) Client() *http.Client {
+	return &http.Client{Transport: r}
+}
+
+// Unused returns the interactions that have not been replayed.
+func (r *Replayer) Unused() []*Interaction {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	var unused []*Interaction
+	for i, in := range r.cassette.Interactions {
+		if !r.used[i] {
+			unused = append(unused, in)
+		}
+	}
+	return unused
+}
+
+// RoundTrip implements http.RoundTripper.
+func (r *Replayer) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mu.Lock()
+	var match *Interaction
+	for i, in := range r.cassette.Interactions {
+		if r.used[i] || in.Request.Method != req.Method || in.Request.Path != req.URL.RequestURI() {
+			continue
+		}
+		if !bodiesEqual([]byte(in.Request.Body), body) {
+			continue
+		}
+		r.used[i] = true
+		match = in
+		break
+	}
+	r.mu.Unlock()
+
+	if match == nil {
+		return nil, fmt.Errorf("testutil: no recorded interaction for %s %s", req.Method, req.URL.RequestURI())
+	}
+
+	resp := &http.Response{
+		StatusCode: match.Response.StatusCode,
+		Header:     match.Response.Headers.Clone(),
+		Request:    req,
+	}
+	if resp.Header == nil {
+		resp.Header = http.Header{}
+	}
+	switch {
+	case match.Response.Lines == nil:
+		resp.Body = io.NopCloser(strings.NewReader(match.Response.Body))
+	case r.Paced:
+		resp.Body = pacedLines(match.Response.Lines)
+	default:
+		var sb strings.Builder
+		for _, line := range match.Response.Lines {
+			sb.WriteString(line.Line + "\n")
+		}
+		resp.Body = io.NopCloser(strings.NewReader(sb.String()))
+	}
+	return resp, nil
+}
+
+// pacedLines writes lines through a pipe, waiting each line's delay first.
+func pacedLines(lines []StreamLine) io.ReadCloser {
+	pr, pw := io.Pipe()
+	go func() {
+		for _, line := range lines {
+			time.Sleep(time.Duration(line.DelayMs) * time.Millisecond)
+			if _, err := pw.Write([]byte(line.Line + "\n")); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+	return pr
+}
+
+// readRequestBody reads req's body and replaces it so it can be sent.
+func readRequestBody(req *http.Request) ([]byte, error) {
+	if req.Body == nil || req.Body == http.NoBody {
+		return nil, nil
+	}
+	data, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("reading request body: %w", err)
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	return data, nil
+}
+
+// bodiesEqual compares two request bodies, as JSON values when both parse.
+func bodiesEqual(a, b []byte) bool {
+	if bytes.Equal(bytes.TrimSpace(a), bytes.TrimSpace(b)) {
+		return true
+	}
+	var av, bv interface{}
+	if json.Unmarshal(a, &av) != nil || json.Unmarshal(b, &bv) != nil {
+		return false
+	}
+	ad, _ := json.Marshal(av)
+	bd, _ := json.Marshal(bv)
+	return bytes.Equal(ad, bd)
+}
@@ -0,0 +1,117 @@
+package testutil
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
+)
+
+func TestCassetteRecordAndReplay(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/v1/agents":
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(`{"object":"list","results":[{"object":"agent","id":"agent-1","name":"Helper"}]}`))
+		case "/v1/agents/agent-1/chatStream":
+			w.Header().Set("Content-Type", "application/x-ndjson")
+			for _, line := range []string{
+				`{"type":"started","thread_id":"t-1","agent_id":"agent-1"}`,
+				`{"type":"message","id":"m-1","role":"agent","content":"Hi"}`,
+				`{"type":"done"}`,
+			} {
+				w.Write([]byte(line + "\n"))
+				w.(http.Flusher).Flush()
+				time.Sleep(20 * time.Millisecond)
+			}
+		default:
+			http.NotFound(w, req)
+		}
+	}))
+	defer srv.Close()
+
+	rec := NewRecorder(nil)
+	run := func(httpClient *http.Client, baseURL string) *notionagents.ThreadInfo {
+		t.Helper()
+		client := notionagents.NewClient(notionagents.ClientOptions{Auth: "secret_token", BaseURL: baseURL, HTTPClient: httpClient})
+		agents, err := client.Agents.List(context.Background(), nil)
+		if err != nil || len(agents.Results) != 1 {
+			t.Fatalf("List = %+v, %v", agents, err)
+		}
+		reader, err := client.Agents.Agent("agent-1").Stream(context.Background(), notionagents.ChatStreamParams{Message: "hi"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer reader.Close()
+		for _, err := range reader.All() {
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		return reader.ThreadInfo()
+	}
+	run(rec.Client(), srv.URL)
+
+	path := filepath.Join(t.TempDir(), "cassette.json")
+	if err := rec.Save(path); err != nil {
+		t.Fatal(err)
+	}
+	data, _ := os.ReadFile(path)
+	if strings.Contains(string(data), "secret_token") {
+		t.Error("cassette contains the API token")
+	}
+
+	cassette, err := LoadCassette(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	stream := cassette.Interactions[1].Response
+	if len(stream.Lines) != 3 {
+		t.Fatalf("recorded %d stream lines, want 3", len(stream.Lines))
+	}
+	if stream.Lines[1].DelayMs < 10 {
+		t.Errorf("line delay = %dms, want the recorded pacing", stream.Lines[1].DelayMs)
+	}
+
+	srv.Close()
+	replayer := NewReplayer(cassette)
+	info := run(replayer.Client(), srv.URL)
+	if info == nil || info.ThreadID != "t-1" || info.Messages[0].Content != "Hi" {
+		t.Errorf("replayed ThreadInfo = %+v", info)
+	}
+	if unused := replayer.Unused(); len(unused) != 0 {
+		t.Errorf("%d interactions were not replayed", len(unused))
+	}
+}
+
+func TestReplayerMatchesBody(t *testing.T) {
+	replayer := NewReplayer(&Cassette{Interactions: []*Interaction{
+		{
+			Request:  RecordedRequest{Method: "POST", Path: "/v1/agents/a/chat", Body: `{"message":"one"}`},
+			Response: RecordedResponse{StatusCode: 200, Body: `{"thread_id":"t-1"}`},
+		},
+		{
+			Request:  RecordedRequest{Method: "POST", Path: "/v1/agents/a/chat", Body: `{"message":"two"}`},
+			Response: RecordedResponse{StatusCode: 200, Body: `{"thread_id":"t-2"}`},
+		},
+	}})
+	client := notionagents.NewClient(notionagents.ClientOptions{Auth: "tok", HTTPClient: replayer.Client()})
+	agent := client.Agents.Agent("a")
+
+	resp, err := agent.Chat(context.Background(), notionagents.ChatParams{Message: "two"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.ThreadID != "t-2" {
+		t.Errorf("ThreadID = %q, want t-2", resp.ThreadID)
+	}
+	if _, err := agent.Chat(context.Background(), notionagents.ChatParams{Message: "three"}); err == nil {
+		t.Error("expected an error for an unrecorded request")
+	}
+}
@@ -0,0 +1,517 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
+)
+
+// defaultPageSize is used by FakeServer when a list request has no page_size.
+const defaultPageSize = 100
+
+// Reply scripts how a FakeServer agent answers a chat message.
+type Reply struct {
+	Text      string                  // Final agent message content
+	ToolCalls []ToolCall              // Tool calls made before the text
+	FollowUps []notionagents.FollowUp // Suggested follow-ups after the text
+	Fail      bool                    // End the thread as failed
+}
+
+// ToolCall is a scripted tool call within a Reply. A non-empty Error marks
+// the call as failed.
+type ToolCall struct {
+	Name   string
+	Input  string
+	Output interface{}
+	Error  string
+}
+
+// FakeServer is an in-process Notion Agents API backed by httptest.Server
+// and in-memory state. It implements listing agents, async and streaming
+// chat, listing threads and listing messages, with cursor pagination.
+//
+// Async chats create threads in ThreadStatusPending. Each thread stays
+// pending for the number of status reads set by SetPendingPolls before the
+// agent's reply is added and the thread completes or fails. Streaming chats
+// complete immediately.
+type FakeServer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	agents       []notionagents.AgentData
+	replies      map[string][]Reply
+	threads      map[string]*fakeThread
+	threadOrder  []string
+	pendingPolls int
+	nextID       int
+}
+
+type fakeThread struct {
+	item         notionagents.ThreadListItem
+	agentID      string
+	messages     []notionagents.ThreadMessageItem
+	pendingReads int
+	reply        Reply
+}
+
+// NewFakeServer starts a FakeServer. Call Close when done.
+func NewFakeServer() *FakeServer {
+	s := &FakeServer{
+		replies:      make(map[string][]Reply),
+		threads:      make(map[string]*fakeThread),
+		pendingPolls: 1,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /v1/agents", s.handleListAgents)
+	mux.HandleFunc("POST /v1/agents/{id}/chat", s.handleChat)
+	mux.HandleFunc("POST /v1/agents/{id}/chatStream", s.handleChatStream)
+	mux.HandleFunc("GET /v1/agents/{id}/threads", s.handleListThreads)
+	mux.HandleFunc("GET /v1/threads/{id}/messages", s.handleListMessages)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Client returns a notionagents.Client that talks to the server.
+func (s *FakeServer) Client() *notionagents.Client {
+	return notionagents.NewClient(notionagents.ClientOptions{
+		Auth:       "fake-token",
+		BaseURL:    s.URL,
+		HTTPClient: s.Server.Client(),
+	})
+}
+
+// AddAgent registers a custom agent and returns it.
+func (s *FakeServer) AddAgent(name string) notionagents.AgentData {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	agent := notionagents.AgentData{
+		Object: "agent",
+		ID:     s.newID("agent"),
+		Name:   name,
+	}
+	s.agents = append(s.agents, agent)
+	return agent
+}
+
+// QueueReply scripts the agent's answers to the next chat messages, in
+// order. Once the queue is empty the agent echoes the message.
+func (s *FakeServer) QueueReply(agentID string, replies ...Reply) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.replies[agentID] = append(s.replies[agentID], replies...)
+}
+
+// SetPendingPolls sets how many status reads a new async thread stays
+// pending for. The default is 1.
+func (s *FakeServer) SetPendingPolls(n int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.pendingPolls = n
+}
+
+// Messages returns the messages of a thread and whether it exists.
+func (s *FakeServer) Messages(threadID string) ([]notionagents.ThreadMessageItem, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	th, ok := s.threads[threadID]
+	if !ok {
+		return nil, false
+	}
+	return append([]notionagents.ThreadMessageItem(nil), th.messages...), true
+}
+
+func (s *FakeServer) newID(prefix string) string {
+	s.nextID++
+	return fmt.Sprintf("%s-%d", prefix, s.nextID)
+}
+
+func (s *FakeServer) hasAgent(id string) bool {
+	if id == notionagents.PersonalAgentID {
+		return true
+	}
+	for _, agent := range s.agents {
+		if agent.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+func (s *FakeServer) handleListAgents(w http.ResponseWriter, req *http.Request) {
+	q := req.URL.Query()
+	s.mu.Lock()
+	var agents []notionagents.AgentData
+	for _, agent := range s.agents {
+		if name := q.Get("name"); name == "" || strings.Contains(strings.ToLower(agent.Name), strings.ToLower(name)) {
+			agents = append(agents, agent)
+		}
+	}
+	s.mu.Unlock()
+
+	page, next, err := paginate(agents, q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, notionagents.AgentListResponse{
+		Object:     "list",
+		Type:       "agent",
+		Results:    page,
+		HasMore:    next != nil,
+		NextCursor: next,
+	})
+}
+
+type fakeChatRequest struct {
+	Message     string            `json:"message"`
+	ThreadID    string            `json:"thread_id"`
+	Attachments []json.RawMessage `json:"attachments"`
+}
+
+// startChat validates a chat request, records the user message and picks
+// the agent's reply. It writes an error response and returns false if the
+// request is invalid.
+func (s *FakeServer) startChat(w http.ResponseWriter, req *http.Request) (*fakeThread, bool) {
+	agentID := req.PathValue("id")
+	var body fakeChatRequest
+	if err := json.NewDecoder(req.Body).Decode(&body); err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", "Invalid request body.")
+		return nil, false
+	}
+	if body.Message == "" && len(body.Attachments) == 0 {
+		writeError(w, http.StatusBadRequest, "validation_error", "Either message or attachments is required.")
+		return nil, false
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.hasAgent(agentID) {
+		writeError(w, http.StatusNotFound, "object_not_found", fmt.Sprintf("Could not find agent with ID: %s.", agentID))
+		return nil, false
+	}
+
+	th, ok := s.threads[body.ThreadID]
+	switch {
+	case body.ThreadID == "":
+		th = &fakeThread{
+			item: notionagents.ThreadListItem{
+				Object:    "thread",
+				ID:        s.newID("thread"),
+				Title:     body.Message,
+				CreatedBy: notionagents.CreatedBy{ID: "fake-user", Type: "user"},
+			},
+			agentID: agentID,
+		}
+		s.threads[th.item.ID] = th
+		s.threadOrder = append(s.threadOrder, th.item.ID)
+	case !ok || th.agentID != agentID:
+		writeError(w, http.StatusNotFound, "object_not_found", fmt.Sprintf("Could not find thread with ID: %s.", body.ThreadID))
+		return nil, false
+	}
+
+	th.messages = append(th.messages, notionagents.ThreadMessageItem{
+		Object:  "thread_message",
+		ID:      s.newID("msg"),
+		Role:    notionagents.MessageRoleUser,
+		Content: body.Message,
+		Parent:  notionagents.MessageParent{Type: "thread", ID: th.item.ID},
+	})
+	th.item.Status = notionagents.ThreadStatusPending
+	th.pendingReads = s.pendingPolls
+
+	th.reply = Reply{Text: "You said: " + body.Message}
+	if queue := s.replies[agentID]; len(queue) > 0 {
+		th.reply = queue[0]
+		s.replies[agentID] = queue[1:]
+	}
+	return th, true
+}
+
+// finish adds the scripted reply to the thread and sets its final status.
+// The server's mutex must be held.
+func (s *FakeServer) finish(th *fakeThread) notionagents.ThreadMessageItem {
+	msg := notionagents.ThreadMessageItem{
+		Object:       "thread_message",
+		ID:           s.newID("msg"),
+		Role:         notionagents.MessageRoleAgent,
+		Content:      th.reply.Text,
+		Parent:       notionagents.MessageParent{Type: "thread", ID: th.item.ID},
+		ContentParts: replyParts(th.reply, len(th.reply.ToolCalls), true, true),
+	}
+	th.messages = append(th.messages, msg)
+	th.item.Status = notionagents.ThreadStatusCompleted
+	if th.reply.Fail {
+		th.item.Status = notionagents.ThreadStatusFailed
+	}
+	return msg
+}
+
+// replyParts builds the content parts of a reply after the first calls tool
+// calls have started, optionally with their results, text and follow-ups.
+func replyParts(reply Reply, calls int, results, complete bool) []notionagents.AgentContentPart {
+	var parts []notionagents.AgentContentPart
+	for i, call := range reply.ToolCalls[:calls] {
+		id := fmt.Sprintf("call-%d", i+1)
+		part := notionagents.AgentContentPart{
+			Type:       notionagents.ContentPartToolCall,
+			ToolCallID: &id,
+			ToolName:   call.Name,
+			Input:      call.Input,
+		}
+		if results || i < calls-1 {
+			result := notionagents.ToolResult{
+				ID:         "result-" + id,
+				ToolCallID: &id,
+				ToolName:   call.Name,
+				State:      "completed",
+				Input:      call.Input,
+				Output:     call.Output,
+			}
+			if call.Error != "" {
+				result.State = "error"
+				result.Error = &call.Error
+			}
+			part.Results = []notionagents.ToolResult{result}
+		}
+		parts = append(parts, part)
+	}
+	if complete {
+		if reply.Text != "" {
+			parts = append(parts, notionagents.AgentContentPart{Type: notionagents.ContentPartText, Text: reply.Text})
+		}
+		if len(reply.FollowUps) > 0 {
+			parts = append(parts, notionagents.AgentContentPart{Type: notionagents.ContentPartFollowUps, FollowUps: reply.FollowUps})
+		}
+	}
+	return parts
+}
+
+func (s *FakeServer) handleChat(w http.ResponseWriter, req *http.Request) {
+	th, ok := s.startChat(w, req)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, notionagents.ChatInvocationResponse{
+		Object:   "chat_invocation",
+		AgentID:  th.agentID,
+		ThreadID: th.item.ID,
+		Status:   string(notionagents.ThreadStatusPending),
+	})
+}
+
+// handleChatStream streams the reply as cumulative message chunks: each
+// tool call starting and finishing, then the text word by word, then the
+// follow-ups. A failing reply ends with an error chunk.
+func (s *FakeServer) handleChatStream(w http.ResponseWriter, req *http.Request) {
+	th, ok := s.startChat(w, req)
+	if !ok {
+		return
+	}
+	verbose := req.URL.Query().Get("verbose") == "true"
+
+	s.mu.Lock()
+	msg := s.finish(th)
+	reply := th.reply
+	threadID, agentID := th.item.ID, th.agentID
+	s.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	flusher, _ := w.(http.Flusher)
+	send := func(chunk notionagents.StreamChunk) {
+		if !verbose {
+			chunk.ContentParts = nil
+		}
+		data, _ := json.Marshal(chunk)
+		w.Write(append(data, '\n'))
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+	message := func(content string, parts []notionagents.AgentContentPart) {
+		send(notionagents.StreamChunk{
+			Type:         "message",
+			ID:           msg.ID,
+			Role:         notionagents.MessageRoleAgent,
+			Content:      content,
+			ContentParts: parts,
+		})
+	}
+
+	send(notionagents.StreamChunk{Type: "started", ThreadID: threadID, AgentID: agentID})
+	for i := range reply.ToolCalls {
+		message("", replyParts(reply, i+1, false, false))
+		message("", replyParts(reply, i+1, true, false))
+	}
+	if reply.Fail {
+		send(notionagents.StreamChunk{Type: "error", Code: "internal_server_error", Message: "The agent failed to respond."})
+		return
+	}
+	var content string
+	for _, word := range strings.SplitAfter(reply.Text, " ") {
+		content += word
+		if content != reply.Text {
+			message(content, replyParts(reply, len(reply.ToolCalls), true, false))
+		}
+	}
+	message(reply.Text, msg.ContentParts)
+	send(notionagents.StreamChunk{Type: "done"})
+}
+
+// advance counts a status read of a pending thread and finishes it once it
+// has been pending for the configured number of reads. The server's mutex
+// must be held.
+func (s *FakeServer) advance(th *fakeThread) {
+	if th.item.Status != notionagents.ThreadStatusPending {
+		return
+	}
+	if th.pendingReads > 0 {
+		th.pendingReads--
+		return
+	}
+	s.finish(th)
+}
+
+func (s *FakeServer) handleListThreads(w http.ResponseWriter, req *http.Request) {
+	agentID := req.PathValue("id")
+	q := req.URL.Query()
+
+	s.mu.Lock()
+	if !s.hasAgent(agentID) {
+		s.mu.Unlock()
+		writeError(w, http.StatusNotFound, "object_not_found", fmt.Sprintf("Could not find agent with ID: %s.", agentID))
+		return
+	}
+	var threads []notionagents.ThreadListItem
+	for _, id := range s.threadOrder {
+		th := s.threads[id]
+		if th.agentID != agentID {
+			continue
+		}
+		if v := q.Get("id"); v != "" && v != id {
+			continue
+		}
+		if v := q.Get("title"); v != "" && !strings.Contains(th.item.Title, v) {
+			continue
+		}
+		if v := q.Get("created_by_type"); v != "" && v != th.item.CreatedBy.Type {
+			continue
+		}
+		if v := q.Get("created_by_id"); v != "" && v != th.item.CreatedBy.ID {
+			continue
+		}
+		s.advance(th)
+		if v := q.Get("status"); v != "" && v != string(th.item.Status) {
+			continue
+		}
+		threads = append(threads, th.item)
+	}
+	s.mu.Unlock()
+
+	page, next, err := paginate(threads, q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, notionagents.ThreadListResponse{
+		Object:     "list",
+		Type:       "thread",
+		Results:    page,
+		HasMore:    next != nil,
+		NextCursor: next,
+	})
+}
+
+func (s *FakeServer) handleListMessages(w http.ResponseWriter, req *http.Request) {
+	threadID := req.PathValue("id")
+	q := req.URL.Query()
+
+	s.mu.Lock()
+	th, ok := s.threads[threadID]
+	var messages []notionagents.ThreadMessageItem
+	if ok {
+		for _, msg := range th.messages {
+			if role := q.Get("role"); role != "" && role != msg.Role {
+				continue
+			}
+			if q.Get("verbose") != "true" {
+				msg.ContentParts = nil
+			}
+			messages = append(messages, msg)
+		}
+	}
+	s.mu.Unlock()
+
+	if !ok {
+		writeError(w, http.StatusNotFound, "object_not_found", fmt.Sprintf("Could not find thread with ID: %s.", threadID))
+		return
+	}
+	page, next, err := paginate(messages, q)
+	if err != nil {
+		writeError(w, http.StatusBadRequest, "validation_error", err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, notionagents.ThreadMessageListResponse{
+		Object:     "list",
+		Type:       "thread_message",
+		Results:    page,
+		HasMore:    next != nil,
+		NextCursor: next,
+	})
+}
+
+// paginate returns the page of items selected by the start_cursor and
+// page_size query parameters and the cursor of the next page, if any.
+// Cursors are offsets into the full result list.
+func paginate[T any](items []T, q url.Values) ([]T, *string, error) {
+	size := defaultPageSize
+	if v := q.Get("page_size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > defaultPageSize {
+			return nil, nil, fmt.Errorf("page_size should be between 1 and %d.", defaultPageSize)
+		}
+		size = n
+	}
+	start := 0
+	if v := q.Get("start_cursor"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > len(items) {
+			return nil, nil, fmt.Errorf("start_cursor %q is invalid.", v)
+		}
+		start = n
+	}
+
+	end := min(start+size, len(items))
+	page := items[start:end]
+	if page == nil {
+		page = []T{}
+	}
+	if end < len(items) {
+		next := strconv.Itoa(end)
+		return page, &next, nil
+	}
+	return page, nil, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+func writeError(w http.ResponseWriter, status int, code, message string) {
+	writeJSON(w, status, map[string]interface{}{
+		"object":  "error",
+		"status":  status,
+		"code":    code,
+		"message": message,
+	})
+}
@@ -0,0 +1,138 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
+)
+
+var fastPoll = &notionagents.PollThreadOptions{InitialDelayMs: 1, BaseDelayMs: 1, MaxDelayMs: 1}
+
+func TestFakeServerAsyncChat(t *testing.T) {
+	srv := NewFakeServer()
+	defer srv.Close()
+	agent := srv.AddAgent("Helper")
+	srv.SetPendingPolls(2)
+	srv.QueueReply(agent.ID, Reply{
+		Text:      "Found it.",
+		ToolCalls: []ToolCall{{Name: "search", Input: "docs", Output: "3 results"}},
+		FollowUps: []notionagents.FollowUp{{Label: "Open", Message: "Open the first result"}},
+	})
+
+	client := srv.Client()
+	result, err := client.Agents.Agent(agent.ID).Ask(context.Background(), notionagents.ChatParams{Message: "Find docs"}, &notionagents.AskOptions{Poll: fastPoll})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Thread.Status != notionagents.ThreadStatusCompleted {
+		t.Errorf("Status = %q, want completed", result.Thread.Status)
+	}
+	if result.Message.Content != "Found it." {
+		t.Errorf("Content = %q, want %q", result.Message.Content, "Found it.")
+	}
+	if len(result.Message.ContentParts) != 3 {
+		t.Errorf("ContentParts = %+v, want tool call, text and follow-ups", result.Message.ContentParts)
+	}
+
+	// Continuing the thread without a queued reply echoes the message.
+	resp, err := client.Agents.Agent(agent.ID).Chat(context.Background(), notionagents.ChatParams{Message: "Thanks", ThreadID: result.ThreadID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := client.Agents.Agent(agent.ID).PollThread(context.Background(), resp.ThreadID, fastPoll); err != nil {
+		t.Fatal(err)
+	}
+	messages, _ := srv.Messages(result.ThreadID)
+	if len(messages) != 4 || messages[3].Content != "You said: Thanks" {
+		t.Errorf("messages = %+v", messages)
+	}
+}
+
+func TestFakeServerFailedThread(t *testing.T) {
+	srv := NewFakeServer()
+	defer srv.Close()
+	agent := srv.AddAgent("Helper")
+	srv.QueueReply(agent.ID, Reply{Fail: true, ToolCalls: []ToolCall{{Name: "search", Error: "timeout"}}})
+
+	_, err := srv.Client().Agents.Agent(agent.ID).Ask(context.Background(), notionagents.ChatParams{Message: "hi"}, &notionagents.AskOptions{Poll: fastPoll})
+	var tfe *notionagents.ThreadFailedError
+	if !errors.As(err, &tfe) {
+		t.Fatalf("err = %v, want ThreadFailedError", err)
+	}
+	if len(tfe.ToolErrors) != 1 || tfe.ToolErrors[0].ToolName != "search" {
+		t.Errorf("ToolErrors = %+v", tfe.ToolErrors)
+	}
+}
+
+func TestFakeServerStream(t *testing.T) {
+	srv := NewFakeServer()
+	defer srv.Close()
+	agent := srv.AddAgent("Helper")
+	srv.QueueReply(agent.ID, Reply{
+		Text:      "Hello there friend",
+		ToolCalls: []ToolCall{{Name: "search", Input: "q", Output: "ok"}},
+	})
+
+	reader, err := srv.Client().Agents.Agent(agent.ID).Stream(context.Background(), notionagents.ChatStreamParams{Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var text string
+	var tools int
+	for event, err := range reader.Events() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch e := event.(type) {
+		case notionagents.MessageUpdated:
+			text += e.Delta.Text
+		case notionagents.ToolCallFinished:
+			tools++
+		}
+	}
+	if text != "Hello there friend" {
+		t.Errorf("streamed text = %q", text)
+	}
+	if tools != 1 {
+		t.Errorf("finished tool calls = %d, want 1", tools)
+	}
+}
+
+func TestFakeServerPagination(t *testing.T) {
+	srv := NewFakeServer()
+	defer srv.Close()
+	for i := 0; i < 5; i++ {
+		srv.AddAgent("Agent")
+	}
+	client := srv.Client()
+
+	page, err := client.Agents.List(context.Background(), &notionagents.AgentListParams{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(page.Results) != 2 || !page.HasMore || page.NextCursor == nil {
+		t.Fatalf("first page = %+v", page)
+	}
+
+	agents, err := notionagents.CollectAgents(context.Background(), client, &notionagents.AgentListParams{PageSize: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(agents) != 5 {
+		t.Errorf("collected %d agents, want 5", len(agents))
+	}
+}
+
+func TestFakeServerNotFound(t *testing.T) {
+	srv := NewFakeServer()
+	defer srv.Close()
+
+	_, err := srv.Client().Agents.Agent("missing").Chat(context.Background(), notionagents.ChatParams{Message: "hi"})
+	if !errors.Is(err, notionagents.ErrAgentNotFound) {
+		t.Errorf("err = %v, want ErrAgentNotFound", err)
+	}
+}
@@ -0,0 +1,290 @@
+package testutil
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Fault alters how a request is handled. It may answer the request itself,
+// fail it, or call next and alter the response.
+type Fault func(req *http.Request, next http.RoundTripper) (*http.Response, error)
+
+// FaultRule injects a fault into matching requests.
+//
+// A rule matches requests by Method and by Path, a path.Match pattern such
+// as "/v1/agents/*/chatStream"; empty fields match everything. Matching
+// requests are counted from 1. If On is set the fault is injected on those
+// request numbers; otherwise it is injected with Probability, or always
+// when Probability is zero.
+type FaultRule struct {
+	Method      string
+	Path        string
+	On          []int
+	Probability float64
+	Fault       Fault
+}
+
+// FaultTransport is an http.RoundTripper that injects faults into requests
+// before passing them to the next transport. The first rule that fires
+// handles the request.
+type FaultTransport struct {
+	next  http.RoundTripper
+	rules []FaultRule
+
+	mu       sync.Mutex
+	rand     *rand.Rand
+	counts   []int
+	injected int
+}
+
+// NewFaultTransport returns a FaultTransport in front of next, or
+// http.DefaultTransport if nil. next can be a RoundTripFunc, so faults can
+// be layered over mocked responses as well as a real server.
+// Probabilistic rules use a fixed seed; see Seed.
+func NewFaultTransport(next http.RoundTripper, rules ...FaultRule) *FaultTransport {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &FaultTransport{
+		next:   next,
+		rules:  rules,
+		rand:   rand.New(rand.NewPCG(1, 1)),
+		counts: make([]int, len(rules)),
+	}
+}
+
+// Seed reseeds the random source used by probabilistic rules.
+func (t *FaultTransport) Seed(seed uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.rand = rand.New(rand.NewPCG(seed, seed))
+}
+
+// Client returns an *http.Client that sends requests through t.
+func (t *FaultTransport) Client() *http.Client {
+	return &http.Client{Transport: t}
+}
+
+// Injected returns the number of faults injected so far.
+func (t *FaultTransport) Injected() int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.injected
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *FaultTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if fault := t.pick(req); fault != nil {
+		return fault(req, t.next)
+	}
+	return t.next.RoundTrip(req)
+}
+
+// pick counts req against every matching rule and returns the fault of the
+// first rule that fires.
+func (t *FaultTransport) pick(req *http.Request) Fault {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var fault Fault
+	for i, rule := range t.rules {
+		if !rule.matches(req) {
+			continue
+		}
+		t.counts[i]++
+		if fault == nil && t.fires(rule, t.counts[i]) {
+			fault = rule.Fault
+			t.injected++
+		}
+	}
+	return fault
+}
+
+func (r FaultRule) matches(req *http.Request) bool {
+	if r.Method != "" && r.Method != req.Method {
+		return false
+	}
+	if r.Path != "" {
+		if ok, _ := path.Match(r.Path, req.URL.Path); !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// fires reports whether rule injects its fault on the nth matching request.
+// The FaultTransport's mutex must be held.
+func (t *FaultTransport) fires(rule FaultRule, n int) bool {
+	if len(rule.On) > 0 {
+		for _, on := range rule.On {
+			if on == n {
+				return true
+			}
+		}
+		return false
+	}
+	if rule.Probability > 0 {
+		return t.rand.Float64() < rule.Probability
+	}
+	return true
+}
+
+// Compose returns a fault that applies faults in order, each wrapping the
+// ones after it, e.g. Compose(Trickle(d), TruncateStream(3)).
+func Compose(faults ...Fault) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		rt := next
+		for i := len(faults) - 1; i >= 0; i-- {
+			fault, inner := faults[i], rt
+			rt = RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+				return fault(req, inner)
+			})
+		}
+		return rt.RoundTrip(req)
+	}
+}
+
+// Status answers the request with an API error response without sending it.
+func Status(statusCode int, code, message string) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return ErrorResponse(statusCode, code, message), nil
+	}
+}
+
+// RateLimited answers the request with a 429 rate_limited error and the
+// given Retry-After, rounded up to whole seconds as the header requires.
+func RateLimited(retryAfter time.Duration) Fault {
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		resp := ErrorResponse(http.StatusTooManyRequests, "rate_limited", "You have been rate limited.")
+		resp.Header.Set("Retry-After", strconv.Itoa(seconds))
+		return resp, nil
+	}
+}
+
+// BadGateway answers the request with a 502 and a non-JSON body, as a
+// proxy in front of the API would.
+func BadGateway() Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return &http.Response{
+			StatusCode: http.StatusBadGateway,
+			Header:     http.Header{"Content-Type": []string{"text/html"}},
+			Body:       io.NopCloser(strings.NewReader("<html><body>502 Bad Gateway</body></html>")),
+		}, nil
+	}
+}
+
+// ErrConnectionReset is the error returned by ConnectionReset.
+var ErrConnectionReset = errors.New("testutil: connection reset by peer")
+
+// ConnectionReset fails the request with ErrConnectionReset.
+func ConnectionReset() Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		return nil, ErrConnectionReset
+	}
+}
+
+// TruncateStream ends the response body after n lines with
+// io.ErrUnexpectedEOF, as if the connection dropped.
+func TruncateStream(n int) Fault {
+	return editLines(func(i int, line []byte, w io.Writer) error {
+		if i >= n {
+			return io.ErrUnexpectedEOF
+		}
+		_, err := w.Write(line)
+		return err
+	})
+}
+
+// MalformedLine inserts a line that is not valid JSON before line n,
+// counted from 0.
+func MalformedLine(n int) Fault {
+	return editLines(func(i int, line []byte, w io.Writer) error {
+		if i == n {
+			if _, err := io.WriteString(w, "{\"type\": \"message\", \"content\n"); err != nil {
+				return err
+			}
+		}
+		_, err := w.Write(line)
+		return err
+	})
+}
+
+// ErrorChunk replaces the stream from line n onwards, counted from 0, with
+// an error chunk.
+func ErrorChunk(n int, code, message string) Fault {
+	return editLines(func(i int, line []byte, w io.Writer) error {
+		if i < n {
+			_, err := w.Write(line)
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "{\"type\":\"error\",\"code\":%q,\"message\":%q}\n", code, message); err != nil {
+			return err
+		}
+		return errEndBody
+	})
+}
+
+// Trickle delays every line of the response body, simulating a slow
+// stream.
+func Trickle(delay time.Duration) Fault {
+	return editLines(func(i int, line []byte, w io.Writer) error {
+		time.Sleep(delay)
+		_, err := w.Write(line)
+		return err
+	})
+}
+
+// errEndBody is returned by a line edit to end the body without an error.
+var errEndBody = errors.New("end of body")
+
+// editLines returns a fault that passes the response body line by line
+// through edit, which writes the lines to keep. Lines are delivered through
+// a pipe as they are edited. The body ends when edit returns an error; it
+// ends cleanly if that error is errEndBody.
+func editLines(edit func(i int, line []byte, w io.Writer) error) Fault {
+	return func(req *http.Request, next http.RoundTripper) (*http.Response, error) {
+		resp, err := next.RoundTrip(req)
+		if err != nil || resp.Body == nil {
+			return resp, err
+		}
+
+		src := resp.Body
+		pr, pw := io.Pipe()
+		go func() {
+			defer src.Close()
+			r := bufio.NewReader(src)
+			for i := 0; ; i++ {
+				line, err := r.ReadBytes('\n')
+				if len(line) > 0 {
+					if editErr := edit(i, line, pw); editErr != nil {
+						if editErr == errEndBody {
+							editErr = nil
+						}
+						pw.CloseWithError(editErr)
+						return
+					}
+				}
+				if err != nil {
+					if err == io.EOF {
+						err = nil
+					}
+					pw.CloseWithError(err)
+					return
+				}
+			}
+		}()
+		resp.Body = pr
+		return resp, nil
+	}
+}
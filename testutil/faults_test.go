@@ -0,0 +1,126 @@
+package testutil
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
+)
+
+func faultClient(rules ...FaultRule) (*notionagents.Client, *FaultTransport) {
+	mock := RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		if req.URL.Path == "/v1/agents/agent-1/chatStream" {
+			return NDJSONResponse(MockStreamChunks()...), nil
+		}
+		return JSONResponse(200, MockAgentListResponse()), nil
+	})
+	ft := NewFaultTransport(mock, rules...)
+	client := notionagents.NewClient(notionagents.ClientOptions{
+		Auth:        "tok",
+		HTTPClient:  ft.Client(),
+		RetryPolicy: &notionagents.RetryPolicy{MaxAttempts: 4, BaseDelayMs: 1, MaxDelayMs: 1},
+	})
+	return client, ft
+}
+
+func streamErr(t *testing.T, client *notionagents.Client) error {
+	t.Helper()
+	reader, err := client.Agents.Agent("agent-1").Stream(context.Background(), notionagents.ChatStreamParams{Message: "hi"})
+	if err != nil {
+		return err
+	}
+	defer reader.Close()
+	for _, err := range reader.All() {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func TestFaultTransportSequence(t *testing.T) {
+	client, ft := faultClient(
+		FaultRule{Path: "/v1/agents", On: []int{1, 2}, Fault: RateLimited(0)},
+		FaultRule{Path: "/v1/agents", On: []int{3}, Fault: BadGateway()},
+	)
+
+	if _, err := client.Agents.List(context.Background(), nil); err != nil {
+		t.Fatalf("List should succeed after retrying 429s and a 502: %v", err)
+	}
+	if ft.Injected() != 3 {
+		t.Errorf("Injected = %d, want 3", ft.Injected())
+	}
+}
+
+func TestRateLimitedRetryAfter(t *testing.T) {
+	tests := []struct {
+		retryAfter time.Duration
+		want       string
+	}{
+		{0, "0"},
+		{200 * time.Millisecond, "1"},
+		{2 * time.Second, "2"},
+		{2500 * time.Millisecond, "3"},
+	}
+	for _, tt := range tests {
+		req, _ := http.NewRequest("GET", "https://api.notion.com/v1/agents", nil)
+		resp, _ := RateLimited(tt.retryAfter)(req, nil)
+		if got := resp.Header.Get("Retry-After"); got != tt.want {
+			t.Errorf("RateLimited(%s) Retry-After = %q, want %q", tt.retryAfter, got, tt.want)
+		}
+	}
+}
+
+func TestFaultTransportProbability(t *testing.T) {
+	ft := NewFaultTransport(RoundTripFunc(func(req *http.Request) (*http.Response, error) {
+		return JSONResponse(200, nil), nil
+	}), FaultRule{Probability: 0.5, Fault: ConnectionReset()})
+
+	failures := 0
+	for i := 0; i < 200; i++ {
+		req, _ := http.NewRequest("GET", "https://api.notion.com/v1/agents", nil)
+		if _, err := ft.RoundTrip(req); errors.Is(err, ErrConnectionReset) {
+			failures++
+		}
+	}
+	if failures < 60 || failures > 140 {
+		t.Errorf("failures = %d of 200, want about half", failures)
+	}
+}
+
+func TestFaultTransportStreamFaults(t *testing.T) {
+	tests := []struct {
+		name  string
+		fault Fault
+		code  string
+	}{
+		{"truncated", TruncateStream(2), "stream_read_error"},
+		{"malformed", MalformedLine(1), "invalid_stream_response"},
+		{"error chunk", ErrorChunk(2, "internal_server_error", "boom"), "internal_server_error"},
+		{"slow and truncated", Compose(Trickle(time.Millisecond), TruncateStream(1)), "stream_read_error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client, _ := faultClient(FaultRule{Method: "POST", Path: "/v1/agents/*/chatStream", Fault: tt.fault})
+			var se *notionagents.StreamError
+			if err := streamErr(t, client); !errors.As(err, &se) || se.Code != tt.code {
+				t.Errorf("err = %v, want StreamError %s", err, tt.code)
+			}
+		})
+	}
+}
+
+func TestFaultTransportTrickle(t *testing.T) {
+	client, _ := faultClient(FaultRule{Path: "/v1/agents/*/chatStream", Fault: Trickle(5 * time.Millisecond)})
+
+	start := time.Now()
+	if err := streamErr(t, client); err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed < 20*time.Millisecond {
+		t.Errorf("stream took %s, want at least 4 delayed lines", elapsed)
+	}
+}
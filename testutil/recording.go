@@ -0,0 +1,196 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// Call is a request received by a MockTransport.
+type Call struct {
+	Request *http.Request
+	Body    []byte      // Raw request body
+	JSON    interface{} // Body decoded as JSON, nil if empty or not JSON
+}
+
+// MockTransport is an http.RoundTripper that records every request before
+// answering it with a RoundTripFunc, so tests can assert what the SDK sent.
+type MockTransport struct {
+	fn RoundTripFunc
+
+	mu    sync.Mutex
+	calls []*Call
+}
+
+// NewMockTransport returns a MockTransport that answers requests with fn,
+// or with an empty 200 JSON response if fn is nil.
+func NewMockTransport(fn RoundTripFunc) *MockTransport {
+	if fn == nil {
+		fn = func(req *http.Request) (*http.Response, error) {
+			return JSONResponse(http.StatusOK, map[string]interface{}{}), nil
+		}
+	}
+	return &MockTransport{fn: fn}
+}
+
+// Client returns an *http.Client that sends requests through m.
+func (m *MockTransport) Client() *http.Client {
+	return &http.Client{Transport: m}
+}
+
+// RoundTrip implements http.RoundTripper.
+func (m *MockTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	body, err := readRequestBody(req)
+	if err != nil {
+		return nil, err
+	}
+	call := &Call{Request: req, Body: body}
+	if len(body) > 0 {
+		var v interface{}
+		if json.Unmarshal(body, &v) == nil {
+			call.JSON = v
+		}
+	}
+
+	m.mu.Lock()
+	m.calls = append(m.calls, call)
+	m.mu.Unlock()
+	return m.fn(req)
+}
+
+// Calls returns the recorded calls in order.
+func (m *MockTransport) Calls() []*Call {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*Call(nil), m.calls...)
+}
+
+// Reset forgets the recorded calls.
+func (m *MockTransport) Reset() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.calls = nil
+}
+
+// CallsTo returns the recorded calls with the given method and URL path.
+func (m *MockTransport) CallsTo(method, path string) []*Call {
+	var matched []*Call
+	for _, call := range m.Calls() {
+		if call.Request.Method == method && call.Request.URL.Path == path {
+			matched = append(matched, call)
+		}
+	}
+	return matched
+}
+
+// AssertCalled fails the test unless a request with the given method and
+// URL path was made. It returns the most recent matching call, or nil, for
+// further assertions.
+func (m *MockTransport) AssertCalled(tb testing.TB, method, path string) *Call {
+	tb.Helper()
+	matched := m.CallsTo(method, path)
+	if len(matched) == 0 {
+		tb.Errorf("expected %s %s to be called; calls were:\n%s", method, path, m.describe())
+		return nil
+	}
+	return matched[len(matched)-1]
+}
+
+// AssertNotCalled fails the test if a request with the given method and
+// URL path was made.
+func (m *MockTransport) AssertNotCalled(tb testing.TB, method, path string) {
+	tb.Helper()
+	if n := len(m.CallsTo(method, path)); n > 0 {
+		tb.Errorf("expected %s %s not to be called, got %d calls", method, path, n)
+	}
+}
+
+// AssertCallCount fails the test unless exactly n requests with the given
+// method and URL path were made.
+func (m *MockTransport) AssertCallCount(tb testing.TB, method, path string, n int) {
+	tb.Helper()
+	if got := len(m.CallsTo(method, path)); got != n {
+		tb.Errorf("expected %d calls to %s %s, got %d", n, method, path, got)
+	}
+}
+
+func (m *MockTransport) describe() string {
+	calls := m.Calls()
+	if len(calls) == 0 {
+		return "  (none)"
+	}
+	lines := make([]string, len(calls))
+	for i, call := range calls {
+		lines[i] = fmt.Sprintf("  %s %s", call.Request.Method, call.Request.URL.RequestURI())
+	}
+	return strings.Join(lines, "\n")
+}
+
+// AssertQuery fails the test unless the query parameter key has the value
+// want. An empty want asserts that the parameter is absent. It is a no-op
+// on a nil call so it can follow a failed AssertCalled.
+func (c *Call) AssertQuery(tb testing.TB, key, want string) *Call {
+	tb.Helper()
+	if c == nil {
+		return c
+	}
+	q := c.Request.URL.Query()
+	if want == "" && q.Has(key) {
+		tb.Errorf("%s %s: query %s = %q, want absent", c.Request.Method, c.Request.URL.Path, key, q.Get(key))
+	} else if got := q.Get(key); want != "" && got != want {
+		tb.Errorf("%s %s: query %s = %q, want %q", c.Request.Method, c.Request.URL.Path, key, got, want)
+	}
+	return c
+}
+
+// AssertHeader fails the test unless the request header name has the value
+// want.
+func (c *Call) AssertHeader(tb testing.TB, name, want string) *Call {
+	tb.Helper()
+	if c == nil {
+		return c
+	}
+	if got := c.Request.Header.Get(name); got != want {
+		tb.Errorf("%s %s: header %s = %q, want %q", c.Request.Method, c.Request.URL.Path, name, got, want)
+	}
+	return c
+}
+
+// AssertJSONBody fails the test unless the request body is JSON equal to
+// want, which may be a struct, map or JSON string. Objects are compared by
+// value, so key order and formatting do not matter.
+func (c *Call) AssertJSONBody(tb testing.TB, want interface{}) *Call {
+	tb.Helper()
+	if c == nil {
+		return c
+	}
+
+	var wantData []byte
+	switch w := want.(type) {
+	case string:
+		wantData = []byte(w)
+	case []byte:
+		wantData = w
+	default:
+		data, err := json.Marshal(want)
+		if err != nil {
+			tb.Errorf("encoding expected body: %v", err)
+			return c
+		}
+		wantData = data
+	}
+	var wantValue interface{}
+	if err := json.Unmarshal(wantData, &wantValue); err != nil {
+		tb.Errorf("expected body is not JSON: %v", err)
+		return c
+	}
+
+	if c.JSON == nil || !reflect.DeepEqual(c.JSON, wantValue) {
+		tb.Errorf("%s %s: body = %s, want %s", c.Request.Method, c.Request.URL.Path, c.Body, wantData)
+	}
+	return c
+}
@@ -0,0 +1,120 @@
+package testutil
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
+)
+
+func TestMockTransportRecordsChat(t *testing.T) {
+	mock := NewMockTransport(func(req *http.Request) (*http.Response, error) {
+		return JSONResponse(http.StatusOK, MockChatInvocationResponse()), nil
+	})
+	client := notionagents.NewClient(notionagents.ClientOptions{Auth: "secret", HTTPClient: mock.Client()})
+
+	tests := []struct {
+		name   string
+		params notionagents.ChatParams
+		body   interface{}
+	}{
+		{
+			name:   "new thread",
+			params: notionagents.ChatParams{Message: "Hello"},
+			body:   map[string]interface{}{"message": "Hello"},
+		},
+		{
+			name:   "existing thread",
+			params: notionagents.ChatParams{Message: "Again", ThreadID: "thread-1"},
+			body:   `{"thread_id": "thread-1", "message": "Again"}`,
+		},
+		{
+			name: "attachment",
+			params: notionagents.ChatParams{
+				Message:     "See file",
+				Attachments: []notionagents.ChatAttachmentInput{{FileUploadID: "upload-1", Name: "notes.txt"}},
+			},
+			body: map[string]interface{}{
+				"message":     "See file",
+				"attachments": []map[string]interface{}{{"file_upload": map[string]string{"id": "upload-1"}, "name": "notes.txt"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mock.Reset()
+			if _, err := client.Agents.Agent("agent-1").Chat(context.Background(), tt.params); err != nil {
+				t.Fatal(err)
+			}
+			mock.AssertCallCount(t, "POST", "/v1/agents/agent-1/chat", 1)
+			mock.AssertCalled(t, "POST", "/v1/agents/agent-1/chat").
+				AssertHeader(t, "Notion-Version", notionagents.DefaultVersion).
+				AssertHeader(t, "Authorization", "Bearer secret").
+				AssertJSONBody(t, tt.body)
+		})
+	}
+}
+
+func TestMockTransportRecordsListThreads(t *testing.T) {
+	mock := NewMockTransport(func(req *http.Request) (*http.Response, error) {
+		return JSONResponse(http.StatusOK, MockThreadListResponse()), nil
+	})
+	client := notionagents.NewClient(notionagents.ClientOptions{Auth: "secret", HTTPClient: mock.Client()})
+
+	_, err := client.Agents.Agent("agent-1").ListThreads(context.Background(), &notionagents.ThreadListParams{
+		Status:   notionagents.ThreadStatusCompleted,
+		PageSize: 10,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.AssertCalled(t, "GET", "/v1/agents/agent-1/threads").
+		AssertQuery(t, "status", "completed").
+		AssertQuery(t, "page_size", "10").
+		AssertQuery(t, "start_cursor", "")
+	mock.AssertNotCalled(t, "POST", "/v1/agents/agent-1/chat")
+	if calls := mock.Calls(); len(calls) != 1 || calls[0].Body != nil || calls[0].JSON != nil {
+		t.Errorf("Calls() = %+v, want one call without a body", calls)
+	}
+}
+
+// failureTB records failures instead of failing the test.
+type failureTB struct {
+	testing.TB
+	failures []string
+}
+
+func (f *failureTB) Helper() {}
+
+func (f *failureTB) Errorf(format string, args ...interface{}) {
+	f.failures = append(f.failures, fmt.Sprintf(format, args...))
+}
+
+func TestMockTransportAssertionFailures(t *testing.T) {
+	mock := NewMockTransport(nil)
+	client := notionagents.NewClient(notionagents.ClientOptions{Auth: "secret", HTTPClient: mock.Client()})
+	if _, err := client.Agents.Agent("agent-1").Chat(context.Background(), notionagents.ChatParams{Message: "Hello"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tb := &failureTB{TB: t}
+	if call := mock.AssertCalled(tb, "GET", "/v1/agents"); call != nil {
+		t.Error("AssertCalled returned a call for an unmatched request")
+	}
+	// Assertions on the nil call from a failed AssertCalled are no-ops.
+	mock.AssertCalled(tb, "GET", "/v1/agents").AssertHeader(tb, "Notion-Version", "x")
+
+	call := mock.AssertCalled(tb, "POST", "/v1/agents/agent-1/chat")
+	call.AssertHeader(tb, "Notion-Version", "1999-01-01")
+	call.AssertQuery(tb, "page_size", "10")
+	call.AssertJSONBody(tb, map[string]string{"message": "Goodbye"})
+	mock.AssertCallCount(tb, "POST", "/v1/agents/agent-1/chat", 2)
+	mock.AssertNotCalled(tb, "POST", "/v1/agents/agent-1/chat")
+
+	if len(tb.failures) != 7 {
+		t.Errorf("got %d failures, want 7:\n%q", len(tb.failures), tb.failures)
+	}
+}
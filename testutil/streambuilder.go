@@ -0,0 +1,203 @@
+package testutil
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
+)
+
+// StreamBuilder builds realistic NDJSON streaming responses. Message
+// chunks carry cumulative content and content parts the way the API sends
+// them, so each builder call only describes what changed.
+//
+//	resp := testutil.NewStreamBuilder().
+//		Started("thread-1").
+//		ToolCall("search", "roadmap", "3 results").
+//		AgentText("Found ", "3 pages").
+//		Done().
+//		Response()
+type StreamBuilder struct {
+	chunks []notionagents.StreamChunk
+	pace   time.Duration
+
+	messages  int
+	msgID     string
+	content   string
+	parts     []notionagents.AgentContentPart
+	textPart  int
+	toolCalls int
+}
+
+// NewStreamBuilder returns an empty StreamBuilder.
+func NewStreamBuilder() *StreamBuilder {
+	return &StreamBuilder{}
+}
+
+// Started adds a started chunk for threadID.
+func (b *StreamBuilder) Started(threadID string) *StreamBuilder {
+	b.chunks = append(b.chunks, notionagents.StreamChunk{Type: "started", ThreadID: threadID})
+	return b
+}
+
+// NewMessage starts a new agent message. Later calls update it instead of
+// the previous one. Messages are started automatically when needed.
+func (b *StreamBuilder) NewMessage() *StreamBuilder {
+	b.messages++
+	b.msgID = fmt.Sprintf("msg-%d", b.messages)
+	b.content = ""
+	b.parts = nil
+	b.textPart = -1
+	return b
+}
+
+// AgentText appends each piece of text to the current agent message,
+// adding one chunk per piece.
+func (b *StreamBuilder) AgentText(pieces ...string) *StreamBuilder {
+	if b.msgID == "" {
+		b.NewMessage()
+	}
+	for _, piece := range pieces {
+		b.content += piece
+		if b.textPart < 0 {
+			b.textPart = len(b.parts)
+			b.parts = append(b.parts, notionagents.AgentContentPart{Type: notionagents.ContentPartText})
+		}
+		b.parts[b.textPart].Text = b.content
+		b.message()
+	}
+	return b
+}
+
+// ToolCall adds a tool call to the current agent message as two chunks:
+// one when the call starts and one when its output arrives.
+func (b *StreamBuilder) ToolCall(name, input string, output interface{}) *StreamBuilder {
+	if b.msgID == "" {
+		b.NewMessage()
+	}
+	b.toolCalls++
+	id := fmt.Sprintf("call-%d", b.toolCalls)
+	b.parts = append(b.parts, notionagents.AgentContentPart{
+		Type:       notionagents.ContentPartToolCall,
+		ToolCallID: &id,
+		ToolName:   name,
+		Input:      input,
+	})
+	b.message()
+
+	b.parts[len(b.parts)-1].Results = []notionagents.ToolResult{{
+		ID:         "result-" + id,
+		ToolCallID: &id,
+		ToolName:   name,
+		State:      "completed",
+		Input:      input,
+		Output:     output,
+	}}
+	b.message()
+	return b
+}
+
+// FollowUps adds suggested follow-ups to the current agent message.
+func (b *StreamBuilder) FollowUps(followUps ...notionagents.FollowUp) *StreamBuilder {
+	if b.msgID == "" {
+		b.NewMessage()
+	}
+	b.parts = append(b.parts, notionagents.AgentContentPart{
+		Type:      notionagents.ContentPartFollowUps,
+		FollowUps: followUps,
+	})
+	b.message()
+	return b
+}
+
+// Error adds an error chunk.
+func (b *StreamBuilder) Error(code, message string) *StreamBuilder {
+	b.chunks = append(b.chunks, notionagents.StreamChunk{Type: "error", Code: code, Message: message})
+	return b
+}
+
+// Done adds a done chunk.
+func (b *StreamBuilder) Done() *StreamBuilder {
+	b.chunks = append(b.chunks, notionagents.StreamChunk{Type: "done"})
+	return b
+}
+
+// Pace delays each line of the body by d, delivering it through an
+// io.Pipe as a slow network stream would. Zero sends the body at once.
+func (b *StreamBuilder) Pace(d time.Duration) *StreamBuilder {
+	b.pace = d
+	return b
+}
+
+// message adds a chunk with the current state of the agent message.
+func (b *StreamBuilder) message() {
+	b.chunks = append(b.chunks, notionagents.StreamChunk{
+		Type:         "message",
+		ID:           b.msgID,
+		Role:         notionagents.MessageRoleAgent,
+		Content:      b.content,
+		ContentParts: cloneParts(b.parts),
+	})
+}
+
+// cloneParts copies parts deeply enough that later updates to the builder
+// do not change chunks already added.
+func cloneParts(parts []notionagents.AgentContentPart) []notionagents.AgentContentPart {
+	out := make([]notionagents.AgentContentPart, len(parts))
+	for i, part := range parts {
+		part.Results = append([]notionagents.ToolResult(nil), part.Results...)
+		part.FollowUps = append([]notionagents.FollowUp(nil), part.FollowUps...)
+		out[i] = part
+	}
+	return out
+}
+
+// Chunks returns the chunks built so far.
+func (b *StreamBuilder) Chunks() []notionagents.StreamChunk {
+	return append([]notionagents.StreamChunk(nil), b.chunks...)
+}
+
+// Body returns the NDJSON body. With Pace set, lines are written through
+// an io.Pipe with the delay before each line; closing the body stops the
+// writer.
+func (b *StreamBuilder) Body() io.ReadCloser {
+	lines := make([][]byte, len(b.chunks))
+	for i, chunk := range b.chunks {
+		data, _ := json.Marshal(chunk)
+		lines[i] = append(data, '\n')
+	}
+
+	if b.pace <= 0 {
+		var sb strings.Builder
+		for _, line := range lines {
+			sb.Write(line)
+		}
+		return io.NopCloser(strings.NewReader(sb.String()))
+	}
+
+	pace := b.pace
+	pr, pw := io.Pipe()
+	go func() {
+		for _, line := range lines {
+			time.Sleep(pace)
+			if _, err := pw.Write(line); err != nil {
+				return
+			}
+		}
+		pw.Close()
+	}()
+	return pr
+}
+
+// Response returns a 200 NDJSON response with Body.
+func (b *StreamBuilder) Response() *http.Response {
+	return &http.Response{
+		StatusCode: 200,
+		Header:     http.Header{"Content-Type": []string{"application/x-ndjson"}},
+		Body:       b.Body(),
+	}
+}
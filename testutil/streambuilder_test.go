@@ -0,0 +1,74 @@
+package testutil
+
+import (
+	"context"
+	"net/http"
+	"testing"
+	"time"
+
+	notionagents "github.com/brittonhayes/notion-agent-sdk-go"
+)
+
+func TestStreamBuilder(t *testing.T) {
+	b := NewStreamBuilder().
+		Started("thread-1").
+		ToolCall("search", "roadmap", "3 results").
+		AgentText("Hel", "lo").
+		FollowUps(notionagents.FollowUp{Label: "More"}).
+		NewMessage().
+		AgentText("Second").
+		Done()
+
+	chunks := b.Chunks()
+	if len(chunks) != 8 {
+		t.Fatalf("got %d chunks, want 8", len(chunks))
+	}
+	hello := chunks[4]
+	if hello.ID != "msg-1" || hello.Content != "Hello" || len(hello.ContentParts) != 2 {
+		t.Errorf("cumulative chunk = %+v", hello)
+	}
+	if started := chunks[1].ContentParts[0]; len(started.Results) != 0 {
+		t.Error("tool call start chunk should not carry results")
+	}
+	if second := chunks[6]; second.ID != "msg-2" || second.Content != "Second" {
+		t.Errorf("second message chunk = %+v", second)
+	}
+}
+
+func TestStreamBuilderWithStreamReader(t *testing.T) {
+	b := NewStreamBuilder().
+		Started("thread-1").
+		ToolCall("search", "q", "ok").
+		AgentText("Hello", ", world").
+		Done().
+		Pace(2 * time.Millisecond)
+
+	client := notionagents.NewClient(notionagents.ClientOptions{
+		Auth: "tok",
+		HTTPClient: MockHTTPClient(func(req *http.Request) (*http.Response, error) {
+			return b.Response(), nil
+		}),
+	})
+	reader, err := client.Agents.Agent("agent-1").Stream(context.Background(), notionagents.ChatStreamParams{Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reader.Close()
+
+	var text string
+	var tools int
+	for event, err := range reader.Events() {
+		if err != nil {
+			t.Fatal(err)
+		}
+		switch e := event.(type) {
+		case notionagents.MessageUpdated:
+			text += e.Delta.Text
+		case notionagents.ToolCallFinished:
+			tools++
+		}
+	}
+	if text != "Hello, world" || tools != 1 {
+		t.Errorf("text = %q, tool calls = %d", text, tools)
+	}
+}
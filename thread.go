@@ -18,6 +18,7 @@ type Thread struct {
 func (t *Thread) Get(ctx context.Context) (*ThreadListItem, error) {
 	path := fmt.Sprintf("v1/agents/%s/threads?id=%s", t.AgentID, url.QueryEscape(t.ThreadID))
 
+	ctx = withOperation(ctx, Operation{Name: OpThreadGet, AgentID: t.AgentID, ThreadID: t.ThreadID})
 	var resp ThreadListResponse
 	if err := t.client.doJSON(ctx, "GET", path, nil, &resp); err != nil {
 		return nil, err
@@ -58,6 +59,7 @@ func (t *Thread) ListMessages(ctx context.Context, params *ThreadMessageListPara
 		}
 	}
 
+	ctx = withOperation(ctx, Operation{Name: OpThreadMessages, AgentID: t.AgentID, ThreadID: t.ThreadID})
 	var resp ThreadMessageListResponse
 	if err := t.client.doJSON(ctx, "GET", path, nil, &resp); err != nil {
 		return nil, err
@@ -0,0 +1,162 @@
+package notionagents
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Operation names used for tracing spans and reported by OperationFromContext.
+const (
+	OpAgentsList      = "agents.list"
+	OpAgentChat       = "agent.chat"
+	OpAgentChatStream = "agent.chatStream"
+	OpAgentThreads    = "agent.threads"
+	OpThreadGet       = "thread.get"
+	OpThreadMessages  = "thread.messages"
+
+	OpFileUploadsCreate   = "fileUploads.create"
+	OpFileUploadsRetrieve = "fileUploads.retrieve"
+	OpFileUploadsSend     = "fileUploads.send"
+	OpFileUploadsComplete = "fileUploads.complete"
+)
+
+// Tracer starts spans for API calls. It is implemented by adapters for
+// tracing libraries such as OpenTelemetry, which keeps the SDK itself free
+// of dependencies.
+type Tracer interface {
+	// Start begins a span with the given name and returns a context
+	// carrying it.
+	Start(ctx context.Context, name string) (context.Context, Span)
+}
+
+// Span is a single traced API call.
+type Span interface {
+	SetAttribute(key string, value any)
+	RecordError(err error)
+	End()
+}
+
+// Operation describes the SDK call that issued a request.
+type Operation struct {
+	Name     string
+	AgentID  string
+	ThreadID string
+}
+
+type operationKey struct{}
+
+// withOperation returns a context annotated with the calling operation.
+func withOperation(ctx context.Context, op Operation) context.Context {
+	return context.WithValue(ctx, operationKey{}, op)
+}
+
+// OperationFromContext returns the SDK operation that issued a request,
+// typically called with the request's context from inside a Middleware.
+func OperationFromContext(ctx context.Context) (Operation, bool) {
+	op, ok := ctx.Value(operationKey{}).(Operation)
+	return op, ok
+}
+
+// TracingMiddleware starts a span per API call named after the operation.
+// Retried attempts of a call share its span, which records the error of
+// each failed attempt and the final attempt number. The span ends when the
+// call returns, or for streaming calls when the response body is closed.
+// Setting ClientOptions.Tracer installs it automatically.
+func TracingMiddleware(tracer Tracer) Middleware {
+	return func(next Handler) Handler {
+		return func(req *http.Request) (*http.Response, error) {
+			ctx := req.Context()
+			call, _ := ctx.Value(callSpanKey{}).(*callSpan)
+
+			var span Span
+			if call != nil && call.span != nil {
+				span = call.span
+			} else {
+				op, ok := OperationFromContext(ctx)
+				name := op.Name
+				if !ok || name == "" {
+					name = req.Method + " " + req.URL.Path
+				}
+
+				ctx, span = tracer.Start(ctx, name)
+				span.SetAttribute("http.method", req.Method)
+				span.SetAttribute("http.path", req.URL.Path)
+				if op.AgentID != "" {
+					span.SetAttribute("notion.agent_id", op.AgentID)
+				}
+				if op.ThreadID != "" {
+					span.SetAttribute("notion.thread_id", op.ThreadID)
+				}
+				if call != nil {
+					call.span = span
+				}
+			}
+			span.SetAttribute("notion.attempt", attemptFromContext(ctx)+1)
+
+			resp, err := next(req.WithContext(ctx))
+			if err != nil {
+				span.RecordError(err)
+				if call == nil {
+					span.End()
+				}
+				return resp, err
+			}
+
+			span.SetAttribute("http.status_code", resp.StatusCode)
+			if id := resp.Header.Get("x-request-id"); id != "" {
+				span.SetAttribute("notion.request_id", id)
+			}
+			if resp.StatusCode >= 400 {
+				span.RecordError(fmt.Errorf("HTTP %d", resp.StatusCode))
+			}
+			if call != nil {
+				return resp, nil
+			}
+			if resp.Body == nil {
+				span.End()
+				return resp, nil
+			}
+			resp.Body = &spanBody{ReadCloser: resp.Body, span: span}
+			return resp, nil
+		}
+	}
+}
+
+// callSpan holds the span shared by every attempt of a retried call. The
+// first attempt through TracingMiddleware starts it; the retry loop ends
+// it.
+type callSpan struct {
+	span Span
+}
+
+type callSpanKey struct{}
+
+// withCallSpan returns a context whose requests share one span until end
+// is called.
+func withCallSpan(ctx context.Context) (context.Context, *callSpan) {
+	call := &callSpan{}
+	return context.WithValue(ctx, callSpanKey{}, call), call
+}
+
+// end ends the shared span, if an attempt started one.
+func (c *callSpan) end() {
+	if c.span != nil {
+		c.span.End()
+	}
+}
+
+// spanBody ends its span when the response body is closed.
+type spanBody struct {
+	io.ReadCloser
+	span Span
+	once sync.Once
+}
+
+func (b *spanBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.once.Do(b.span.End)
+	return err
+}
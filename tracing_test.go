@@ -0,0 +1,182 @@
+package notionagents
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"sync"
+	"testing"
+)
+
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordingSpan
+}
+
+func (t *recordingTracer) Start(ctx context.Context, name string) (context.Context, Span) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	span := &recordingSpan{name: name, attrs: make(map[string]any)}
+	t.spans = append(t.spans, span)
+	return ctx, span
+}
+
+type recordingSpan struct {
+	name  string
+	attrs map[string]any
+	errs  []error
+	ended int
+}
+
+func (s *recordingSpan) SetAttribute(key string, value any) { s.attrs[key] = value }
+func (s *recordingSpan) RecordError(err error)              { s.errs = append(s.errs, err) }
+func (s *recordingSpan) End()                               { s.ended++ }
+
+func TestTracerSpanPerOperation(t *testing.T) {
+	tracer := &recordingTracer{}
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		switch {
+		case req.URL.Path == "/v1/agents":
+			return jsonResponse(200, AgentListResponse{Object: "list"}), nil
+		case req.URL.Path == "/v1/agents/agent-1/chat":
+			return jsonResponse(200, ChatInvocationResponse{ThreadID: "thread-1"}), nil
+		default:
+			return jsonResponse(200, ThreadMessageListResponse{Object: "list"}), nil
+		}
+	}, ClientOptions{Tracer: tracer})
+	ctx := context.Background()
+
+	if _, err := c.Agents.List(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+	agent := c.Agents.Agent("agent-1")
+	if _, err := agent.Chat(ctx, ChatParams{Message: "hi", ThreadID: "thread-1"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := agent.Thread("thread-1").ListMessages(ctx, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	wantNames := []string{OpAgentsList, OpAgentChat, OpThreadMessages}
+	if len(tracer.spans) != len(wantNames) {
+		t.Fatalf("spans = %d, want %d", len(tracer.spans), len(wantNames))
+	}
+	for i, want := range wantNames {
+		span := tracer.spans[i]
+		if span.name != want {
+			t.Errorf("span[%d].name = %q, want %q", i, span.name, want)
+		}
+		if span.ended != 1 {
+			t.Errorf("span[%d] ended %d times, want 1", i, span.ended)
+		}
+		if span.attrs["http.status_code"] != 200 {
+			t.Errorf("span[%d] status = %v, want 200", i, span.attrs["http.status_code"])
+		}
+	}
+
+	chat := tracer.spans[1]
+	if chat.attrs["notion.agent_id"] != "agent-1" {
+		t.Errorf("agent_id = %v, want agent-1", chat.attrs["notion.agent_id"])
+	}
+	if chat.attrs["notion.thread_id"] != "thread-1" {
+		t.Errorf("thread_id = %v, want thread-1", chat.attrs["notion.thread_id"])
+	}
+}
+
+func TestTracerStreamSpanEndsOnClose(t *testing.T) {
+	tracer := &recordingTracer{}
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return ndjsonResponse(
+			StreamChunk{Type: "started", ThreadID: "t-1", AgentID: "agent-1"},
+			StreamChunk{Type: "done"},
+		), nil
+	}, ClientOptions{Tracer: tracer})
+
+	reader, err := c.Agents.Agent("agent-1").Stream(context.Background(), ChatStreamParams{Message: "hi"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.spans) != 1 || tracer.spans[0].name != OpAgentChatStream {
+		t.Fatalf("spans = %+v, want one %s span", tracer.spans, OpAgentChatStream)
+	}
+	span := tracer.spans[0]
+	if span.ended != 0 {
+		t.Error("stream span should stay open until the body is closed")
+	}
+
+	for {
+		if _, err := reader.Next(); err == io.EOF {
+			break
+		} else if err != nil {
+			t.Fatal(err)
+		}
+	}
+	reader.Close()
+	reader.Close()
+
+	if span.ended != 1 {
+		t.Errorf("span ended %d times, want 1", span.ended)
+	}
+}
+
+func TestTracerRecordsErrorStatus(t *testing.T) {
+	tracer := &recordingTracer{}
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		return jsonResponse(500, map[string]interface{}{"object": "error", "status": 500, "code": "internal_server_error", "message": "boom"}), nil
+	}, ClientOptions{Tracer: tracer})
+
+	if _, err := c.Agents.List(context.Background(), nil); err == nil {
+		t.Fatal("expected error, got nil")
+	}
+	if len(tracer.spans[0].errs) != 1 {
+		t.Errorf("recorded errors = %d, want 1", len(tracer.spans[0].errs))
+	}
+}
+
+func TestTracerOneSpanAcrossRetries(t *testing.T) {
+	tracer := &recordingTracer{}
+	attempts := 0
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		if attempts < 3 {
+			return jsonResponse(503, map[string]interface{}{"object": "error", "status": 503, "code": "service_unavailable", "message": "busy"}), nil
+		}
+		return jsonResponse(200, ChatInvocationResponse{ThreadID: "thread-1"}), nil
+	}, ClientOptions{
+		Tracer:      tracer,
+		RetryPolicy: &RetryPolicy{MaxAttempts: 3, BaseDelayMs: 1, MaxDelayMs: 1},
+	})
+
+	if _, err := c.Agents.Agent("agent-1").Chat(context.Background(), ChatParams{Message: "hi"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(tracer.spans) != 1 {
+		t.Fatalf("spans = %d, want one span for the call", len(tracer.spans))
+	}
+	span := tracer.spans[0]
+	if span.name != OpAgentChat || span.ended != 1 {
+		t.Errorf("span = %q ended %d times, want %q ended once", span.name, span.ended, OpAgentChat)
+	}
+	if span.attrs["notion.attempt"] != 3 || span.attrs["http.status_code"] != 200 {
+		t.Errorf("attrs = %v, want attempt 3 with status 200", span.attrs)
+	}
+	if len(span.errs) != 2 {
+		t.Errorf("recorded errors = %d, want one per failed attempt", len(span.errs))
+	}
+}
+
+func TestOperationFromContext(t *testing.T) {
+	var got Operation
+	c := mockClient(func(req *http.Request) (*http.Response, error) {
+		got, _ = OperationFromContext(req.Context())
+		return jsonResponse(200, ThreadListResponse{Object: "list", Results: []ThreadListItem{{ID: "t-1"}}}), nil
+	})
+
+	if _, err := c.Agents.Agent("agent-1").GetThread(context.Background(), "t-1"); err != nil {
+		t.Fatal(err)
+	}
+	want := Operation{Name: OpThreadGet, AgentID: "agent-1", ThreadID: "t-1"}
+	if got != want {
+		t.Errorf("operation = %+v, want %+v", got, want)
+	}
+}
@@ -20,6 +20,12 @@ const (
 	ThreadStatusFailed    ThreadStatus = "failed"
 )
 
+// Message roles reported in ThreadMessageItem.Role.
+const (
+	MessageRoleUser  = "user"
+	MessageRoleAgent = "agent"
+)
+
 // AgentVersion contains version information for an agent.
 type AgentVersion struct {
 	ID          string `json:"id"`
@@ -125,6 +131,14 @@ type AgentContentPart struct {
 	FollowUps  []FollowUp   `json:"follow_ups,omitempty"`
 }
 
+// Content part types reported in AgentContentPart.Type.
+const (
+	ContentPartText      = "text"
+	ContentPartThinking  = "thinking"
+	ContentPartToolCall  = "tool_call"
+	ContentPartFollowUps = "follow_ups"
+)
+
 // ThreadMessageItem represents a message within a thread.
 type ThreadMessageItem struct {
 	Object       string                    `json:"object"`
@@ -221,6 +235,29 @@ type StreamChunk struct {
 	ContentParts []AgentContentPart        `json:"content_parts,omitempty"`
 	Code         string                    `json:"code,omitempty"`
 	Message      string                    `json:"message,omitempty"`
+
+	// Delta describes what changed in this message since the previous chunk
+	// for the same message ID. It is set by StreamReader on message chunks.
+	Delta *StreamDelta `json:"-"`
+}
+
+// StreamDelta is the change a message chunk makes to its message.
+type StreamDelta struct {
+	// Text is the content appended since the previous chunk, or the full
+	// content when Replace is set.
+	Text string
+	// Replace reports that the message was edited rather than extended:
+	// the content no longer starts with the previous content, or content
+	// parts were removed. Text and Parts then hold the whole message.
+	Replace bool
+	// Parts holds the content parts that are new or changed since the
+	// previous chunk, or all content parts when Replace is set.
+	Parts []AgentContentPart
+	// PartIndexes holds the index in ContentParts of each entry in Parts.
+	// An index below the previous number of parts replaces the part at that
+	// index, such as a text part growing or a tool call gaining results;
+	// any other index appends a new part.
+	PartIndexes []int
 }
 
 // StreamMessage represents an accumulated message from a stream.
@@ -247,6 +284,24 @@ type PollThreadOptions struct {
 	InitialDelayMs   int
 	OnPending        func(thread ThreadListItem, attempt int)
 	OnThreadNotFound func(attempt int)
+
+	// Strict returns a *ThreadFailedError with failure details instead of
+	// the thread when it finishes with ThreadStatusFailed.
+	Strict bool
+}
+
+// AskOptions configures Agent.Ask.
+type AskOptions struct {
+	Poll    *PollThreadOptions // Optional: polling behavior, defaults as for PollThread
+	Verbose *bool              // Optional: include content_parts in messages, defaults to true
+}
+
+// AskResult is the outcome of a completed Agent.Ask round-trip.
+type AskResult struct {
+	ThreadID string
+	Thread   ThreadListItem      // Final thread status and metadata
+	Message  ThreadMessageItem   // The agent's final reply
+	Messages []ThreadMessageItem // All messages in the thread
 }
 
 // ChatParams configures a chat request.
@@ -263,4 +318,76 @@ type ChatStreamParams struct {
 	ThreadID    string
 	Verbose     *bool
 	OnMessage   func(message StreamMessage)
+
+	// Resumable falls back to polling the thread and fetching its messages
+	// when the connection drops after the stream has started, so the reader
+	// still emits the remaining message chunks and a final done chunk.
+	Resumable  bool
+	ResumePoll *PollThreadOptions // Optional: polling used when resuming
+
+	// MaxLineSize limits the size of a single NDJSON line in bytes. Zero
+	// uses ClientOptions.MaxStreamLineSize, negative means unlimited.
+	MaxLineSize int
+
+	// IdleTimeoutMs aborts the stream with a StreamIdleError when no line
+	// arrives within the timeout, regardless of how long the whole
+	// response takes. Resumable streams fall back to polling instead.
+	IdleTimeoutMs int
+}
+
+// FileUploadStatus represents the status of a file upload.
+type FileUploadStatus string
+
+const (
+	FileUploadStatusPending  FileUploadStatus = "pending"
+	FileUploadStatusUploaded FileUploadStatus = "uploaded"
+	FileUploadStatusExpired  FileUploadStatus = "expired"
+	FileUploadStatusFailed   FileUploadStatus = "failed"
+)
+
+// FileUploadMode selects how a file is sent to the API.
+type FileUploadMode string
+
+const (
+	FileUploadModeSinglePart FileUploadMode = "single_part"
+	FileUploadModeMultiPart  FileUploadMode = "multi_part"
+)
+
+// FileUploadParts reports multi-part upload progress.
+type FileUploadParts struct {
+	Total int `json:"total"`
+	Sent  int `json:"sent"`
+}
+
+// FileUpload represents a file upload returned by the API.
+type FileUpload struct {
+	Object        string           `json:"object"`
+	ID            string           `json:"id"`
+	CreatedTime   string           `json:"created_time"`
+	ExpiryTime    *string          `json:"expiry_time"`
+	Status        FileUploadStatus `json:"status"`
+	Filename      string           `json:"filename"`
+	ContentType   string           `json:"content_type"`
+	ContentLength *int64           `json:"content_length"`
+	UploadURL     string           `json:"upload_url,omitempty"`
+	CompleteURL   string           `json:"complete_url,omitempty"`
+	NumberOfParts *FileUploadParts `json:"number_of_parts,omitempty"`
+}
+
+// FileUploadCreateParams configures creating a file upload.
+type FileUploadCreateParams struct {
+	Mode          FileUploadMode `json:"mode,omitempty"`
+	Filename      string         `json:"filename,omitempty"`
+	ContentType   string         `json:"content_type,omitempty"`
+	NumberOfParts int            `json:"number_of_parts,omitempty"`
+}
+
+// FileUploadParams configures FileUploadOperations.Upload.
+type FileUploadParams struct {
+	Filename    string                  // Required: name of the file
+	ContentType string                  // Optional: detected from the filename or content when empty
+	Size        int64                   // Optional: detected from the reader when possible
+	PartSize    int64                   // Optional: multi-part chunk size, defaults to DefaultUploadPartSize
+	MaxSize     int64                   // Optional: reject files larger than this many bytes
+	OnProgress  func(sent, total int64) // Optional: called after each part is sent
 }